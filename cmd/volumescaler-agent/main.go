@@ -0,0 +1,45 @@
+// Command volumescaler-agent runs as a DaemonSet on every node. It measures
+// mountpoint usage for locally-scheduled pods and serves it over HTTP so the
+// cluster-wide volumescaler-controller can read it on demand.
+package main
+
+import (
+	"flag"
+	"os"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	"github.com/zghanem0/volumescaler/pkg/agent"
+)
+
+func main() {
+	var addr, tokenFile string
+	flag.StringVar(&addr, "listen-address", ":9100", "address the agent's stats HTTP endpoint listens on")
+	flag.StringVar(&tokenFile, "token-file", "", "path to the shared secret volumescaler-controller must present to query this agent")
+	klog.InitFlags(nil)
+	flag.Parse()
+	defer klog.Flush()
+
+	nodeName := os.Getenv("NODE_NAME_ENV")
+	if nodeName == "" {
+		klog.Fatal("NODE_NAME_ENV not set, exiting.")
+	}
+
+	if tokenFile == "" {
+		klog.Fatal("--token-file is required, exiting.")
+	}
+	data, err := os.ReadFile(tokenFile)
+	if err != nil {
+		klog.Fatalf("reading --token-file: %v", err)
+	}
+	token := strings.TrimSpace(string(data))
+
+	srv, err := agent.NewServer(nodeName, addr, token)
+	if err != nil {
+		klog.Fatalf("agent: %v", err)
+	}
+	if err := srv.Run(); err != nil {
+		klog.Fatalf("agent: %v", err)
+	}
+}
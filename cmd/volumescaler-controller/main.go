@@ -0,0 +1,161 @@
+// Command volumescaler-controller runs the cluster-wide VolumeScaler
+// reconciler. It replaces the old per-node agent loop: a single elected
+// replica watches every PVC, Pod and VolumeScaler in the cluster and
+// patches PVCs through a rate-limited workqueue instead of every node
+// racing to do the same work.
+package main
+
+import (
+	"context"
+	"flag"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/klog/v2"
+
+	"github.com/zghanem0/volumescaler/pkg/controller"
+	"github.com/zghanem0/volumescaler/pkg/metrics/source"
+)
+
+const resyncPeriod = 10 * time.Minute
+
+func main() {
+	var (
+		leaderElection          bool
+		leaderElectionNamespace string
+		retryIntervalStart      time.Duration
+		retryIntervalMax        time.Duration
+		resizeTimeout           time.Duration
+		agentPort               int
+		agentTokenFile          string
+		workers                 int
+		metricsSourceKind       string
+		kubeletPort             int
+		prometheusURL           string
+		metricsAddress          string
+	)
+
+	flag.BoolVar(&leaderElection, "leader-election", true, "enable leader election so only one controller replica is active at a time")
+	flag.StringVar(&leaderElectionNamespace, "leader-election-namespace", "kube-system", "namespace holding the leader election lock")
+	flag.DurationVar(&retryIntervalStart, "retry-interval-start", 1*time.Second, "initial backoff between retries of a failed sync")
+	flag.DurationVar(&retryIntervalMax, "retry-interval-max", 5*time.Minute, "maximum backoff between retries of a failed sync")
+	flag.DurationVar(&resizeTimeout, "resize-timeout", 30*time.Second, "timeout for a single CSI-triggered resize call")
+	flag.IntVar(&agentPort, "agent-port", 9100, "port the node-local volumescaler-agent listens on, when --metrics-source=agent")
+	flag.StringVar(&agentTokenFile, "agent-token-file", "", "path to the shared secret used to authenticate to volumescaler-agent, when --metrics-source=agent")
+	flag.IntVar(&workers, "workers", 2, "number of concurrent workqueue workers")
+	flag.StringVar(&metricsSourceKind, "metrics-source", "kubelet", "how to measure PVC usage: kubelet, prometheus, or agent")
+	flag.IntVar(&kubeletPort, "kubelet-port", 10250, "kubelet's read/stats port, when --metrics-source=kubelet")
+	flag.StringVar(&prometheusURL, "prometheus-url", "", "base URL of a Prometheus server to query, when --metrics-source=prometheus")
+	flag.StringVar(&metricsAddress, "metrics-address", ":9101", "address the controller's own /metrics endpoint listens on")
+	klog.InitFlags(nil)
+	flag.Parse()
+	defer klog.Flush()
+
+	config, err := rest.InClusterConfig()
+	if err != nil {
+		klog.Fatalf("building in-cluster config: %v", err)
+	}
+
+	kubeClient, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		klog.Fatalf("building kube client: %v", err)
+	}
+
+	dynClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		klog.Fatalf("building dynamic client: %v", err)
+	}
+
+	var agentToken string
+	if agentTokenFile != "" {
+		data, err := os.ReadFile(agentTokenFile)
+		if err != nil {
+			klog.Fatalf("reading --agent-token-file: %v", err)
+		}
+		agentToken = strings.TrimSpace(string(data))
+	}
+
+	metricsSource, err := source.New(metricsSourceKind, kubeletPort, prometheusURL, agentPort, agentToken)
+	if err != nil {
+		klog.Fatalf("building metrics source: %v", err)
+	}
+
+	opts := controller.Options{
+		ResizeTimeout:      resizeTimeout,
+		RetryIntervalStart: retryIntervalStart,
+		RetryIntervalMax:   retryIntervalMax,
+	}
+
+	go func() {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", promhttp.Handler())
+		klog.InfoS("controller: serving metrics", "address", metricsAddress)
+		if err := http.ListenAndServe(metricsAddress, mux); err != nil {
+			klog.Fatalf("serving metrics: %v", err)
+		}
+	}()
+
+	run := func(ctx context.Context) {
+		kubeInformers := informers.NewSharedInformerFactory(kubeClient, resyncPeriod)
+		dynInformers := dynamicinformer.NewDynamicSharedInformerFactory(dynClient, resyncPeriod)
+
+		ctrl := controller.NewController(kubeInformers, dynInformers, kubeClient, dynClient, metricsSource, opts)
+		if err := ctrl.Run(ctx, workers); err != nil {
+			klog.Fatalf("controller exited: %v", err)
+		}
+	}
+
+	ctx := context.Background()
+
+	if !leaderElection {
+		run(ctx)
+		return
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		klog.Fatalf("determining hostname: %v", err)
+	}
+
+	lock := &resourcelock.LeaseLock{
+		LeaseMeta: metav1.ObjectMeta{
+			Name:      "volumescaler-controller",
+			Namespace: leaderElectionNamespace,
+		},
+		Client: kubeClient.CoordinationV1(),
+		LockConfig: resourcelock.ResourceLockConfig{
+			Identity: hostname,
+		},
+	}
+
+	leaderelection.RunOrDie(ctx, leaderelection.LeaderElectionConfig{
+		Lock:            lock,
+		ReleaseOnCancel: true,
+		LeaseDuration:   15 * time.Second,
+		RenewDeadline:   10 * time.Second,
+		RetryPeriod:     2 * time.Second,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: run,
+			OnStoppedLeading: func() {
+				klog.InfoS("controller: lost leadership, exiting")
+				os.Exit(0)
+			},
+			OnNewLeader: func(identity string) {
+				if identity != hostname {
+					klog.InfoS("controller: new leader elected", "identity", identity)
+				}
+			},
+		},
+	})
+}
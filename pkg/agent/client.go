@@ -0,0 +1,54 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Client lets the controller fetch mount stats from the agent running on a
+// given node, rather than reading the mountpoint itself.
+type Client struct {
+	port       int
+	token      string
+	httpClient *http.Client
+}
+
+// NewClient returns a Client that queries node-local agents on the given
+// port, authenticating with the shared token every agent is configured
+// with.
+func NewClient(port int, token string) *Client {
+	return &Client{
+		port:       port,
+		token:      token,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Stats fetches the mount usage for the given pod/PVC pair from the agent
+// listening on nodeIP.
+func (c *Client) Stats(nodeIP, podUID, pvcUID string) (MountStats, error) {
+	url := fmt.Sprintf("http://%s:%d/stats?podUID=%s&pvcUID=%s", nodeIP, c.port, podUID, pvcUID)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return MountStats{}, fmt.Errorf("building request to agent at %s: %w", nodeIP, err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return MountStats{}, fmt.Errorf("querying agent at %s: %w", nodeIP, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return MountStats{}, fmt.Errorf("agent at %s returned status %d", nodeIP, resp.StatusCode)
+	}
+
+	var stats MountStats
+	if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+		return MountStats{}, fmt.Errorf("decoding agent response from %s: %w", nodeIP, err)
+	}
+	return stats, nil
+}
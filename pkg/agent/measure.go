@@ -0,0 +1,45 @@
+package agent
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// Measure shells out to df against mountPath and returns the used/capacity
+// bytes it reports. This is the same node-local measurement the original
+// agent loop performed inline on every node; it now runs once per node
+// behind an HTTP endpoint instead of racing a copy of the controller logic
+// across every node that happens to host a watched pod.
+func Measure(mountPath string) (MountStats, error) {
+	out, err := exec.Command("df", mountPath).CombinedOutput()
+	if err != nil {
+		return MountStats{}, fmt.Errorf("running df on %s: %w", mountPath, err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	if len(lines) < 2 {
+		return MountStats{}, fmt.Errorf("unexpected df output for %s: %q", mountPath, out)
+	}
+
+	fields := strings.Fields(lines[1])
+	if len(fields) < 4 {
+		return MountStats{}, fmt.Errorf("unexpected df fields for %s: %v", mountPath, fields)
+	}
+
+	totalBlocks, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return MountStats{}, fmt.Errorf("parsing total blocks for %s: %w", mountPath, err)
+	}
+	usedBlocks, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil {
+		return MountStats{}, fmt.Errorf("parsing used blocks for %s: %w", mountPath, err)
+	}
+
+	// df reports in 1024-byte blocks by default.
+	return MountStats{
+		UsedBytes:     usedBlocks * 1024,
+		CapacityBytes: totalBlocks * 1024,
+	}, nil
+}
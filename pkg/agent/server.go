@@ -0,0 +1,95 @@
+package agent
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+
+	"k8s.io/klog/v2"
+)
+
+// uidPattern matches the Kubernetes UID format (a lowercase RFC 4122 UUID),
+// the only values podUID/pvcUID may legitimately take. Rejecting anything
+// else before it reaches the mount path keeps a caller from walking the
+// hostPath tree with "../" or other path-traversal payloads.
+var uidPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+
+// Server exposes this node's mount usage over HTTP so the cluster-wide
+// controller can read it on demand instead of every node running its own
+// copy of the scaling loop.
+type Server struct {
+	nodeName string
+	addr     string
+	token    string
+}
+
+// NewServer returns a Server that will listen on addr once Run is called.
+// token is the shared secret the controller must present on every request;
+// callers that don't present it are refused. An empty token is rejected by
+// NewServer since the DaemonSet's hostPath access makes an unauthenticated
+// /stats endpoint a path-traversal-adjacent privilege escalation.
+func NewServer(nodeName, addr, token string) (*Server, error) {
+	if token == "" {
+		return nil, fmt.Errorf("agent: token must not be empty")
+	}
+	return &Server{nodeName: nodeName, addr: addr, token: token}, nil
+}
+
+// Run starts the HTTP server and blocks until it exits.
+func (s *Server) Run() error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/stats", s.handleStats)
+	klog.InfoS("agent: serving mount stats", "node", s.nodeName, "address", s.addr)
+	return http.ListenAndServe(s.addr, mux)
+}
+
+// handleStats serves the usage of a single pod/PVC mount, identified by the
+// pod and PVC UIDs that make up the kubelet mount path.
+func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(r) {
+		http.Error(w, "missing or invalid bearer token", http.StatusUnauthorized)
+		return
+	}
+
+	podUID := r.URL.Query().Get("podUID")
+	pvcUID := r.URL.Query().Get("pvcUID")
+	if podUID == "" || pvcUID == "" {
+		http.Error(w, "podUID and pvcUID query parameters are required", http.StatusBadRequest)
+		return
+	}
+	if !uidPattern.MatchString(podUID) || !uidPattern.MatchString(pvcUID) {
+		http.Error(w, "podUID and pvcUID must be UUIDs", http.StatusBadRequest)
+		return
+	}
+
+	mountPath := fmt.Sprintf("/var/lib/kubelet/pods/%s/volumes/kubernetes.io~csi/pvc-%s/mount", podUID, pvcUID)
+	if _, err := os.Stat(mountPath); os.IsNotExist(err) {
+		http.Error(w, fmt.Sprintf("mount path does not exist: %s", mountPath), http.StatusNotFound)
+		return
+	}
+
+	stats, err := Measure(mountPath)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(stats); err != nil {
+		klog.ErrorS(err, "agent: encoding stats response")
+	}
+}
+
+// authorized reports whether r carries the shared bearer token, compared in
+// constant time so response timing can't be used to brute-force it.
+func (s *Server) authorized(r *http.Request) bool {
+	const prefix = "Bearer "
+	auth := r.Header.Get("Authorization")
+	if len(auth) != len(prefix)+len(s.token) || auth[:len(prefix)] != prefix {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(auth[len(prefix):]), []byte(s.token)) == 1
+}
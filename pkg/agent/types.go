@@ -0,0 +1,12 @@
+// Package agent implements the DaemonSet-mode half of volumescaler: a
+// node-local process that measures mountpoint usage and publishes it over
+// HTTP so the cluster-wide controller can read it without itself needing a
+// privileged hostPath mount on every node.
+package agent
+
+// MountStats reports the observed usage of a single CSI volume mount, as
+// measured directly on the node that hosts it.
+type MountStats struct {
+	UsedBytes     int64 `json:"usedBytes"`
+	CapacityBytes int64 `json:"capacityBytes"`
+}
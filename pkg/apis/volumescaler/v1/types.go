@@ -0,0 +1,164 @@
+// Package v1 defines the in-memory representation of the zghanem.aws/v1
+// VolumeScaler custom resource. There is no generated clientset for this
+// type; it is decoded from the dynamic client's unstructured form via
+// runtime.DefaultUnstructuredConverter, same as the original agent did.
+package v1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// GroupName is the API group VolumeScaler is served under.
+	GroupName = "zghanem.aws"
+	// Version is the API version VolumeScaler is served under.
+	Version = "v1"
+	// Resource is the plural resource name used with the dynamic client.
+	Resource = "volumescalers"
+)
+
+// VolumeScalerSpec is the user-supplied scaling policy for a single PVC.
+type VolumeScalerSpec struct {
+	PVCName string `json:"pvcName"`
+	// Threshold is either a percentage ("70%", scale when utilization
+	// reaches it) or an absolute quantity ("10Gi", scale when free space
+	// drops below it), parsed via pkg/quantity.
+	Threshold string `json:"threshold"`
+	// Scale is either a percentage ("30%", grow by that fraction of the
+	// current size) or an absolute quantity ("10Gi", grow by that much),
+	// parsed via pkg/quantity.
+	Scale   string `json:"scale"`
+	MaxSize string `json:"maxSize"` // e.g., "100Gi"
+
+	// TargetStorageClass is the StorageClass to migrate the PVC to once
+	// MigrationPolicy triggers. Required for MigrationPolicy values other
+	// than None.
+	TargetStorageClass string `json:"targetStorageClass,omitempty"`
+	// MigrationPolicy controls when a migration to TargetStorageClass is
+	// attempted.
+	MigrationPolicy MigrationPolicy `json:"migrationPolicy,omitempty"`
+	// SnapshotClassName is the VolumeSnapshotClass used to snapshot the
+	// source PVC during migration.
+	SnapshotClassName string `json:"snapshotClassName,omitempty"`
+
+	// Predictive configures scaling ahead of Threshold being crossed, by
+	// projecting usage forward from its recent growth rate. Scaling is
+	// purely reactive when nil.
+	Predictive *PredictiveSpec `json:"predictive,omitempty"`
+}
+
+// PredictiveSpec configures projecting a PVC's future usage from its
+// recent growth rate, so a scale-up can be triggered ahead of Threshold
+// actually being crossed.
+type PredictiveSpec struct {
+	// WindowDuration bounds how far back usage samples are kept when
+	// fitting the growth-rate regression, e.g. "1h".
+	WindowDuration string `json:"windowDuration"`
+	// MinSamples is the fewest in-window samples required before a
+	// projection is trusted.
+	MinSamples int `json:"minSamples"`
+	// LeadTime is how far into the future usage is projected, e.g. "15m".
+	// A scale-up is triggered now if the projected usage at now+LeadTime
+	// would cross Threshold.
+	LeadTime string `json:"leadTime"`
+}
+
+// MigrationPolicy controls when VolumeScaler migrates a PVC to
+// spec.targetStorageClass instead of (or in addition to) growing it in
+// place.
+type MigrationPolicy string
+
+const (
+	// MigrationPolicyNone never migrates; the PVC stops growing once it
+	// reaches maxSize.
+	MigrationPolicyNone MigrationPolicy = "None"
+	// MigrationPolicyOnMaxSize migrates once the PVC has reached maxSize.
+	MigrationPolicyOnMaxSize MigrationPolicy = "OnMaxSize"
+	// MigrationPolicyAlways migrates as soon as the PVC's StorageClass
+	// differs from targetStorageClass, regardless of size.
+	MigrationPolicyAlways MigrationPolicy = "Always"
+)
+
+// VolumeScalerStatus reflects the last observed state of a scaling policy.
+type VolumeScalerStatus struct {
+	ScaledAt       string `json:"scaledAt,omitempty"`
+	ReachedMaxSize bool   `json:"reachedMaxSize,omitempty"`
+
+	// ResizeInProgress is true from the moment the PVC is patched until the
+	// waiter observes the new capacity and all resize conditions have
+	// cleared. No further scale-up is issued while it is true.
+	ResizeInProgress bool `json:"resizeInProgress,omitempty"`
+	// LastObservedCapacity is the PVC's status.capacity.storage as last
+	// observed by the resize waiter.
+	LastObservedCapacity string `json:"lastObservedCapacity,omitempty"`
+	// Conditions mirrors the PVC's resize-related conditions so users can
+	// see pending filesystem expansion without cross-referencing the PVC.
+	Conditions []VolumeScalerCondition `json:"conditions,omitempty"`
+
+	// Migration tracks an in-progress or completed StorageClass migration
+	// triggered by MigrationPolicy.
+	Migration *VolumeScalerMigrationStatus `json:"migration,omitempty"`
+
+	// PredictedFullAt is the RFC3339 time at which the Predictive
+	// growth-rate projection estimates usage will reach the PVC's current
+	// capacity, if Spec.Predictive is set and the trend is growing.
+	PredictedFullAt string `json:"predictedFullAt,omitempty"`
+	// Samples persists the in-window usage samples backing the Predictive
+	// projection, so a controller restart doesn't lose history mid-window.
+	Samples []PredictiveSample `json:"samples,omitempty"`
+}
+
+// PredictiveSample is a single (timestamp, usedBytes) observation backing
+// the Predictive growth-rate projection.
+type PredictiveSample struct {
+	Time      string `json:"time"`
+	UsedBytes int64  `json:"usedBytes"`
+}
+
+// VolumeScalerMigrationStatus tracks the progress of a StorageClass
+// migration: Snapshotting -> Provisioning -> Swapping -> Repointing ->
+// Completed, or Failed (with rollback of anything created so far) at any
+// step up through Swapping.
+type VolumeScalerMigrationStatus struct {
+	Phase        string `json:"phase,omitempty"`
+	SnapshotName string `json:"snapshotName,omitempty"`
+	NewPVCName   string `json:"newPVCName,omitempty"`
+	Message      string `json:"message,omitempty"`
+
+	// WorkloadKind, WorkloadName and Replicas identify the Deployment or
+	// StatefulSet paused and swapped onto NewPVCName, and its replica
+	// count before pausing. They are captured once, on entering Swapping,
+	// so the pause/swap/resume steps can be retried from a pod that no
+	// longer mounts the source PVC (e.g. after a successful pause).
+	WorkloadKind string `json:"workloadKind,omitempty"`
+	WorkloadName string `json:"workloadName,omitempty"`
+	Replicas     int32  `json:"replicas,omitempty"`
+}
+
+// VolumeScalerCondition mirrors corev1.PersistentVolumeClaimCondition.
+type VolumeScalerCondition struct {
+	Type               string                 `json:"type"`
+	Status             corev1.ConditionStatus `json:"status"`
+	LastProbeTime      metav1.Time            `json:"lastProbeTime,omitempty"`
+	LastTransitionTime metav1.Time            `json:"lastTransitionTime,omitempty"`
+	Reason             string                 `json:"reason,omitempty"`
+	Message            string                 `json:"message,omitempty"`
+}
+
+// VolumeScaler is the decoded form of a zghanem.aws/v1 VolumeScaler object.
+type VolumeScaler struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   VolumeScalerSpec   `json:"spec"`
+	Status VolumeScalerStatus `json:"status,omitempty"`
+}
+
+// VolumeScalerList is a list of VolumeScalers.
+type VolumeScalerList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []VolumeScaler `json:"items"`
+}
@@ -0,0 +1,181 @@
+// Package controller implements the cluster-wide VolumeScaler reconciler.
+// Unlike the original per-node agent loop, a single controller replica
+// (elected via leader election in cmd/volumescaler-controller) watches
+// every PVC, Pod and VolumeScaler in the cluster through shared informers
+// and reconciles them through a rate-limited workqueue keyed by
+// "namespace/pvcName", so patching logic runs once globally instead of
+// racing across every node that happens to host a watched pod.
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	scalerv1 "github.com/zghanem0/volumescaler/pkg/apis/volumescaler/v1"
+	"github.com/zghanem0/volumescaler/pkg/metrics/source"
+	"github.com/zghanem0/volumescaler/pkg/migration"
+	"github.com/zghanem0/volumescaler/pkg/predictive"
+	"github.com/zghanem0/volumescaler/pkg/resize"
+)
+
+// GVR identifies the VolumeScaler custom resource served by the API server.
+var GVR = schema.GroupVersionResource{
+	Group:    scalerv1.GroupName,
+	Version:  scalerv1.Version,
+	Resource: scalerv1.Resource,
+}
+
+// Options configures the behavior of a Controller.
+type Options struct {
+	// ResizeTimeout bounds how long a single CSI-triggered resize is given
+	// to complete before it is considered failed.
+	ResizeTimeout time.Duration
+	// RetryIntervalStart and RetryIntervalMax bound the exponential backoff
+	// applied to workqueue retries of a failed sync.
+	RetryIntervalStart time.Duration
+	RetryIntervalMax   time.Duration
+}
+
+// Controller reconciles VolumeScaler policies against the PVCs they target.
+type Controller struct {
+	kubeClient kubernetes.Interface
+	dynClient  dynamic.Interface
+
+	pvcLister corelisters.PersistentVolumeClaimLister
+	podLister corelisters.PodLister
+
+	pvcInformer cache.SharedIndexInformer
+	podInformer cache.SharedIndexInformer
+	vsInformer  cache.SharedIndexInformer
+
+	queue workqueue.RateLimitingInterface
+
+	metricsSource source.Source
+	waiter        *resize.Waiter
+	migrator      *migration.Migrator
+	predictive    *predictive.Store
+	recorder      record.EventRecorder
+	opts          Options
+}
+
+// NewController wires up informer event handlers and returns a Controller
+// ready to Run. The supplied factories must not have been started yet.
+// metricsSource is already constructed by the caller since its concrete
+// type (kubelet, Prometheus, or node-local agent) depends on flags that
+// don't otherwise belong on Options.
+func NewController(
+	kubeInformers informers.SharedInformerFactory,
+	dynInformers dynamicinformer.DynamicSharedInformerFactory,
+	kubeClient kubernetes.Interface,
+	dynClient dynamic.Interface,
+	metricsSource source.Source,
+	opts Options,
+) *Controller {
+	pvcInformer := kubeInformers.Core().V1().PersistentVolumeClaims()
+	podInformer := kubeInformers.Core().V1().Pods()
+	vsInformer := dynInformers.ForResource(GVR).Informer()
+
+	rateLimiter := workqueue.NewItemExponentialFailureRateLimiter(opts.RetryIntervalStart, opts.RetryIntervalMax)
+
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartLogging(klog.Infof)
+	eventBroadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: kubeClient.CoreV1().Events("")})
+	recorder := eventBroadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: "volumescaler-controller"})
+
+	c := &Controller{
+		kubeClient:    kubeClient,
+		dynClient:     dynClient,
+		pvcLister:     pvcInformer.Lister(),
+		podLister:     podInformer.Lister(),
+		pvcInformer:   pvcInformer.Informer(),
+		podInformer:   podInformer.Informer(),
+		vsInformer:    vsInformer,
+		queue:         workqueue.NewRateLimitingQueue(rateLimiter),
+		metricsSource: metricsSource,
+		waiter:        resize.NewWaiter(kubeClient, opts.ResizeTimeout),
+		migrator:      migration.NewMigrator(kubeClient, dynClient),
+		predictive:    predictive.NewStore(),
+		recorder:      recorder,
+		opts:          opts,
+	}
+
+	pvcInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    c.enqueuePVC,
+		UpdateFunc: func(_, newObj interface{}) { c.enqueuePVC(newObj) },
+	})
+
+	vsInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    c.enqueueVolumeScaler,
+		UpdateFunc: func(_, newObj interface{}) { c.enqueueVolumeScaler(newObj) },
+	})
+
+	return c
+}
+
+// Run starts the informers and blocks until ctx is cancelled, processing
+// work items with the given number of concurrent workers.
+func (c *Controller) Run(ctx context.Context, workers int) error {
+	defer c.queue.ShutDown()
+
+	go c.pvcInformer.Run(ctx.Done())
+	go c.podInformer.Run(ctx.Done())
+	go c.vsInformer.Run(ctx.Done())
+
+	if !cache.WaitForCacheSync(ctx.Done(), c.pvcInformer.HasSynced, c.podInformer.HasSynced, c.vsInformer.HasSynced) {
+		return fmt.Errorf("timed out waiting for informer caches to sync")
+	}
+
+	klog.InfoS("caches synced, starting workers", "workers", workers)
+
+	for i := 0; i < workers; i++ {
+		go func() {
+			for c.processNextWorkItem(ctx) {
+			}
+		}()
+	}
+
+	<-ctx.Done()
+	return nil
+}
+
+func (c *Controller) enqueuePVC(obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		klog.ErrorS(err, "failed to get key for PVC")
+		return
+	}
+	c.queue.Add(key)
+}
+
+func (c *Controller) enqueueVolumeScaler(obj interface{}) {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return
+	}
+
+	var vs scalerv1.VolumeScaler
+	if err := fromUnstructured(u, &vs); err != nil {
+		klog.ErrorS(err, "failed to decode VolumeScaler", "volumeScaler", u.GetName())
+		return
+	}
+	if vs.Spec.PVCName == "" {
+		return
+	}
+	c.queue.Add(vs.Namespace + "/" + vs.Spec.PVCName)
+}
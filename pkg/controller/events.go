@@ -0,0 +1,30 @@
+package controller
+
+import (
+	corev1 "k8s.io/api/core/v1"
+
+	scalerv1 "github.com/zghanem0/volumescaler/pkg/apis/volumescaler/v1"
+)
+
+// Event reasons recorded against a VolumeScaler. These match what a user would grep for in `kubectl describe`
+// or `kubectl get events` to understand why (or why not) a PVC was resized.
+const (
+	ReasonScalingVolume          = "ScalingVolume"
+	ReasonScaledVolume           = "ScaledVolume"
+	ReasonReachedMaxSize         = "ReachedMaxSize"
+	ReasonScalingSkippedCooldown = "ScalingSkippedCooldown"
+	ReasonScalingFailed          = "ScalingFailed"
+	ReasonInvalidSpec            = "InvalidSpec"
+)
+
+// volumeScalerRef builds an object reference for a VolumeScaler CR so
+// events can be recorded against it even though it's only ever read
+// through the dynamic client, not a generated typed client.
+func volumeScalerRef(namespace, name string) *corev1.ObjectReference {
+	return &corev1.ObjectReference{
+		APIVersion: scalerv1.GroupName + "/" + scalerv1.Version,
+		Kind:       "VolumeScaler",
+		Namespace:  namespace,
+		Name:       name,
+	}
+}
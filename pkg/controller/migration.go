@@ -0,0 +1,281 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+
+	scalerv1 "github.com/zghanem0/volumescaler/pkg/apis/volumescaler/v1"
+	"github.com/zghanem0/volumescaler/pkg/migration"
+)
+
+// reconcileMigration drives a StorageClass migration forward by one step,
+// if scaler's MigrationPolicy calls for one. It returns handled=true when
+// migration logic owns this sync (the caller should not also attempt a
+// reactive resize in the same pass).
+func (c *Controller) reconcileMigration(ctx context.Context, namespace, pvcName, scalerName string, scaler *scalerv1.VolumeScaler, pvc *corev1.PersistentVolumeClaim) (handled bool, err error) {
+	if scaler.Spec.MigrationPolicy == "" || scaler.Spec.MigrationPolicy == scalerv1.MigrationPolicyNone {
+		return false, nil
+	}
+	if scaler.Spec.TargetStorageClass == "" {
+		return false, nil
+	}
+
+	var currentClass string
+	if pvc.Spec.StorageClassName != nil {
+		currentClass = *pvc.Spec.StorageClassName
+	}
+	if currentClass == scaler.Spec.TargetStorageClass {
+		return false, nil
+	}
+
+	if scaler.Status.Migration == nil {
+		switch scaler.Spec.MigrationPolicy {
+		case scalerv1.MigrationPolicyAlways:
+			return true, c.startMigration(ctx, namespace, pvcName, scalerName, scaler)
+		case scalerv1.MigrationPolicyOnMaxSize:
+			if scaler.Status.ReachedMaxSize {
+				return true, c.startMigration(ctx, namespace, pvcName, scalerName, scaler)
+			}
+		}
+		return false, nil
+	}
+
+	phase := migration.Phase(scaler.Status.Migration.Phase)
+	if phase == migration.PhaseCompleted || phase == migration.PhaseFailed {
+		return false, nil
+	}
+	return true, c.advanceMigration(ctx, namespace, pvcName, scalerName, scaler)
+}
+
+func (c *Controller) startMigration(ctx context.Context, namespace, pvcName, scalerName string, scaler *scalerv1.VolumeScaler) error {
+	plan := migration.Plan{
+		Namespace:          namespace,
+		SourcePVCName:      pvcName,
+		TargetStorageClass: scaler.Spec.TargetStorageClass,
+		SnapshotClassName:  scaler.Spec.SnapshotClassName,
+	}
+
+	snapName, err := c.migrator.Snapshot(ctx, plan)
+	if err != nil {
+		return c.failMigration(ctx, namespace, scalerName, &scalerv1.VolumeScalerMigrationStatus{}, fmt.Sprintf("creating snapshot: %v", err))
+	}
+	klog.InfoS("migration: created snapshot", "namespace", namespace, "volumeScaler", scalerName, "snapshot", snapName)
+	return c.patchMigrationStatus(ctx, namespace, scalerName, scalerv1.VolumeScalerMigrationStatus{
+		Phase:        string(migration.PhaseSnapshotting),
+		SnapshotName: snapName,
+	})
+}
+
+func (c *Controller) advanceMigration(ctx context.Context, namespace, pvcName, scalerName string, scaler *scalerv1.VolumeScaler) error {
+	mig := scaler.Status.Migration
+
+	switch migration.Phase(mig.Phase) {
+	case migration.PhaseSnapshotting:
+		ready, err := c.migrator.SnapshotReady(ctx, namespace, mig.SnapshotName)
+		if err != nil {
+			return c.failMigration(ctx, namespace, scalerName, mig, err.Error())
+		}
+		if !ready {
+			return nil
+		}
+
+		pvc, err := c.pvcLister.PersistentVolumeClaims(namespace).Get(pvcName)
+		if err != nil {
+			return c.failMigration(ctx, namespace, scalerName, mig, err.Error())
+		}
+		plan := migration.Plan{Namespace: namespace, SourcePVCName: pvcName, TargetStorageClass: scaler.Spec.TargetStorageClass}
+		newPVCName, err := c.migrator.ProvisionPVC(ctx, plan, mig.SnapshotName, *pvc.Spec.Resources.Requests.Storage())
+		if err != nil {
+			return c.failMigration(ctx, namespace, scalerName, mig, err.Error())
+		}
+		klog.InfoS("migration: provisioning PVC", "namespace", namespace, "volumeScaler", scalerName, "pvc", newPVCName)
+		next := *mig
+		next.Phase = string(migration.PhaseProvisioning)
+		next.NewPVCName = newPVCName
+		return c.patchMigrationStatus(ctx, namespace, scalerName, next)
+
+	case migration.PhaseProvisioning:
+		newPVC, err := c.kubeClient.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, mig.NewPVCName, metav1.GetOptions{})
+		if err != nil {
+			return c.failMigration(ctx, namespace, scalerName, mig, err.Error())
+		}
+		waitsForFirstConsumer, err := c.waitsForFirstConsumer(ctx, scaler.Spec.TargetStorageClass)
+		if err != nil {
+			return c.failMigration(ctx, namespace, scalerName, mig, err.Error())
+		}
+		// A WaitForFirstConsumer target StorageClass (the common default
+		// for topology-aware CSI drivers) leaves the PVC Pending until a
+		// pod actually mounts it, which only happens once PhaseSwapping
+		// resumes the workload onto it. Gating on ClaimBound here would
+		// deadlock every such migration, so only require it for an
+		// Immediate-binding target class.
+		if !waitsForFirstConsumer && newPVC.Status.Phase != corev1.ClaimBound {
+			return nil
+		}
+
+		// Resolve the workload while it still mounts the source PVC (the
+		// next step pauses it, after which no pod mounts pvcName) and
+		// persist it on status so PhaseSwapping's steps can be retried
+		// without needing to re-derive it.
+		kind, workloadName, replicas, err := c.findWorkloadForPVC(ctx, namespace, pvcName)
+		if err != nil {
+			return c.failMigration(ctx, namespace, scalerName, mig, err.Error())
+		}
+		next := *mig
+		next.Phase = string(migration.PhaseSwapping)
+		next.WorkloadKind = kind
+		next.WorkloadName = workloadName
+		next.Replicas = replicas
+		return c.patchMigrationStatus(ctx, namespace, scalerName, next)
+
+	case migration.PhaseSwapping:
+		kind, workloadName := mig.WorkloadKind, mig.WorkloadName
+		if err := c.migrator.PauseWorkload(ctx, namespace, kind, workloadName); err != nil {
+			return c.failSwap(ctx, namespace, scalerName, mig, err)
+		}
+		if err := c.migrator.SwapPVC(ctx, namespace, kind, workloadName, pvcName, mig.NewPVCName); err != nil {
+			return c.failSwap(ctx, namespace, scalerName, mig, err)
+		}
+		if err := c.migrator.ResumeWorkload(ctx, namespace, kind, workloadName, mig.Replicas); err != nil {
+			// The workload's pod template already references the new
+			// PVC and is in live use: do not roll anything back. Leave
+			// the migration in Swapping so the next sync retries the
+			// (idempotent) resume alone.
+			klog.ErrorS(err, "migration: resuming workload after PVC swap, will retry", "namespace", namespace, "volumeScaler", scalerName, "workload", workloadName)
+			return err
+		}
+		klog.InfoS("migration: swapped workload onto migrated PVC", "namespace", namespace, "volumeScaler", scalerName, "workloadKind", kind, "workload", workloadName, "pvc", mig.NewPVCName)
+
+		next := *mig
+		next.Phase = string(migration.PhaseRepointing)
+		return c.patchMigrationStatus(ctx, namespace, scalerName, next)
+
+	case migration.PhaseRepointing:
+		if err := c.repointPVCName(ctx, namespace, scalerName, mig.NewPVCName); err != nil {
+			return fmt.Errorf("repointing VolumeScaler %s/%s at migrated PVC %s: %w", namespace, scalerName, mig.NewPVCName, err)
+		}
+		next := *mig
+		next.Phase = string(migration.PhaseCompleted)
+		return c.patchMigrationStatus(ctx, namespace, scalerName, next)
+	}
+
+	return nil
+}
+
+// failSwap resumes the workload to its pre-pause replica count, best
+// effort, before failing the migration: a Pause or SwapPVC error in
+// PhaseSwapping must not leave the workload scaled to zero forever, since
+// Failed is a terminal phase reconcileMigration never revisits.
+func (c *Controller) failSwap(ctx context.Context, namespace, scalerName string, mig *scalerv1.VolumeScalerMigrationStatus, cause error) error {
+	if mig.WorkloadKind != "" && mig.WorkloadName != "" {
+		if err := c.migrator.ResumeWorkload(ctx, namespace, mig.WorkloadKind, mig.WorkloadName, mig.Replicas); err != nil {
+			klog.ErrorS(err, "migration: resuming workload after failed swap", "namespace", namespace, "volumeScaler", scalerName, "workload", mig.WorkloadName)
+		}
+	}
+	return c.failMigration(ctx, namespace, scalerName, mig, cause.Error())
+}
+
+func (c *Controller) failMigration(ctx context.Context, namespace, scalerName string, mig *scalerv1.VolumeScalerMigrationStatus, message string) error {
+	klog.InfoS("migration failed", "namespace", namespace, "volumeScaler", scalerName, "message", message)
+	c.migrator.Rollback(ctx, namespace, mig.SnapshotName, mig.NewPVCName)
+	next := *mig
+	next.Phase = string(migration.PhaseFailed)
+	next.Message = message
+	return c.patchMigrationStatus(ctx, namespace, scalerName, next)
+}
+
+// repointPVCName patches spec.pvcName so the scaler resolves newPVCName on
+// every future sync, now that the workload has been swapped onto it. It
+// patches the main resource rather than the status subresource, since
+// spec is not writable through "status".
+func (c *Controller) repointPVCName(ctx context.Context, namespace, scalerName, newPVCName string) error {
+	body, err := json.Marshal(map[string]interface{}{"spec": map[string]interface{}{"pvcName": newPVCName}})
+	if err != nil {
+		return fmt.Errorf("marshaling pvcName patch: %w", err)
+	}
+	_, err = c.dynClient.Resource(GVR).Namespace(namespace).Patch(ctx, scalerName, types.MergePatchType, body, metav1.PatchOptions{})
+	return err
+}
+
+func (c *Controller) patchMigrationStatus(ctx context.Context, namespace, scalerName string, status scalerv1.VolumeScalerMigrationStatus) error {
+	body, err := json.Marshal(migrationStatusPatch{Status: migrationStatusFields{Migration: status}})
+	if err != nil {
+		return fmt.Errorf("marshaling migration status: %w", err)
+	}
+
+	_, err = c.dynClient.Resource(GVR).Namespace(namespace).Patch(ctx, scalerName, types.MergePatchType, body, metav1.PatchOptions{}, "status")
+	return err
+}
+
+type migrationStatusFields struct {
+	Migration scalerv1.VolumeScalerMigrationStatus `json:"migration"`
+}
+
+type migrationStatusPatch struct {
+	Status migrationStatusFields `json:"status"`
+}
+
+// findWorkloadForPVC returns the Kind ("Deployment" or "StatefulSet"), name
+// and current replica count of the workload that owns the pod currently
+// mounting pvcName.
+func (c *Controller) findWorkloadForPVC(ctx context.Context, namespace, pvcName string) (kind, name string, replicas int32, err error) {
+	pod, err := c.findPodForPVC(namespace, pvcName)
+	if err != nil {
+		return "", "", 0, err
+	}
+	if pod == nil {
+		return "", "", 0, fmt.Errorf("no pod currently mounts PVC %s/%s", namespace, pvcName)
+	}
+
+	for _, ref := range pod.OwnerReferences {
+		switch ref.Kind {
+		case "StatefulSet":
+			sts, err := c.kubeClient.AppsV1().StatefulSets(namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+			if err != nil {
+				return "", "", 0, fmt.Errorf("getting StatefulSet %s/%s: %w", namespace, ref.Name, err)
+			}
+			return "StatefulSet", ref.Name, replicasOrDefault(sts.Spec.Replicas), nil
+		case "ReplicaSet":
+			rs, err := c.kubeClient.AppsV1().ReplicaSets(namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+			if err != nil {
+				return "", "", 0, fmt.Errorf("getting ReplicaSet %s/%s: %w", namespace, ref.Name, err)
+			}
+			for _, rsRef := range rs.OwnerReferences {
+				if rsRef.Kind != "Deployment" {
+					continue
+				}
+				dep, err := c.kubeClient.AppsV1().Deployments(namespace).Get(ctx, rsRef.Name, metav1.GetOptions{})
+				if err != nil {
+					return "", "", 0, fmt.Errorf("getting Deployment %s/%s: %w", namespace, rsRef.Name, err)
+				}
+				return "Deployment", rsRef.Name, replicasOrDefault(dep.Spec.Replicas), nil
+			}
+		}
+	}
+	return "", "", 0, fmt.Errorf("pod %s/%s is not owned by a Deployment or StatefulSet", namespace, pod.Name)
+}
+
+func replicasOrDefault(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
+
+// waitsForFirstConsumer reports whether storageClassName uses
+// WaitForFirstConsumer binding, under which a PVC stays Pending until a
+// pod mounts it.
+func (c *Controller) waitsForFirstConsumer(ctx context.Context, storageClassName string) (bool, error) {
+	sc, err := c.kubeClient.StorageV1().StorageClasses().Get(ctx, storageClassName, metav1.GetOptions{})
+	if err != nil {
+		return false, fmt.Errorf("getting StorageClass %s: %w", storageClassName, err)
+	}
+	return sc.VolumeBindingMode != nil && *sc.VolumeBindingMode == storagev1.VolumeBindingWaitForFirstConsumer, nil
+}
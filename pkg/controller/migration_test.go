@@ -0,0 +1,202 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+
+	scalerv1 "github.com/zghanem0/volumescaler/pkg/apis/volumescaler/v1"
+	"github.com/zghanem0/volumescaler/pkg/migration"
+)
+
+func int32ptr(i int32) *int32 { return &i }
+
+func bindingMode(m storagev1.VolumeBindingMode) *storagev1.VolumeBindingMode { return &m }
+
+func TestWaitsForFirstConsumer(t *testing.T) {
+	cases := []struct {
+		name string
+		sc   *storagev1.StorageClass
+		want bool
+	}{
+		{
+			name: "wait for first consumer",
+			sc:   &storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "topology-aware"}, VolumeBindingMode: bindingMode(storagev1.VolumeBindingWaitForFirstConsumer)},
+			want: true,
+		},
+		{
+			name: "immediate",
+			sc:   &storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "immediate"}, VolumeBindingMode: bindingMode(storagev1.VolumeBindingImmediate)},
+			want: false,
+		},
+		{
+			name: "unset binding mode defaults to Immediate behavior",
+			sc:   &storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "unset"}},
+			want: false,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := &Controller{kubeClient: kubefake.NewSimpleClientset(tc.sc)}
+			got, err := c.waitsForFirstConsumer(context.Background(), tc.sc.Name)
+			if err != nil {
+				t.Fatalf("waitsForFirstConsumer: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("waitsForFirstConsumer(%s) = %v, want %v", tc.sc.Name, got, tc.want)
+			}
+		})
+	}
+}
+
+func newTestController(kubeObjects []runtime.Object, dynObjects []runtime.Object) *Controller {
+	scheme := runtime.NewScheme()
+	gvrToListKind := map[schema.GroupVersionResource]string{GVR: "VolumeScalerList"}
+	dynClient := fake.NewSimpleDynamicClientWithCustomListKinds(scheme, gvrToListKind, dynObjects...)
+	kubeClient := kubefake.NewSimpleClientset(kubeObjects...)
+	return &Controller{
+		kubeClient: kubeClient,
+		dynClient:  dynClient,
+		migrator:   migration.NewMigrator(kubeClient, dynClient),
+	}
+}
+
+func testVolumeScaler(namespace, name, pvcName string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": scalerv1.GroupName + "/" + scalerv1.Version,
+		"kind":       "VolumeScaler",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": namespace,
+		},
+		"spec": map[string]interface{}{
+			"pvcName": pvcName,
+		},
+	}}
+}
+
+func deployment(namespace, name string, replicas int32, pvcName string) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: int32ptr(replicas),
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Volumes: []corev1.Volume{{
+						Name: "data",
+						VolumeSource: corev1.VolumeSource{
+							PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: pvcName},
+						},
+					}},
+				},
+			},
+		},
+	}
+}
+
+// TestAdvanceMigrationRepointRetriesAlone exercises the PhaseSwapping ->
+// PhaseRepointing -> PhaseCompleted path: once Pause/Swap/Resume have all
+// succeeded, a retry (e.g. because repointPVCName failed previously) must
+// only retry the repoint, not re-run Pause/Swap/Resume against a workload
+// that's already fully migrated onto the new PVC.
+func TestAdvanceMigrationRepointRetriesAlone(t *testing.T) {
+	dep := deployment("ns", "app", 3, "src")
+	vs := testVolumeScaler("ns", "vs1", "src")
+	c := newTestController([]runtime.Object{dep}, []runtime.Object{vs})
+	ctx := context.Background()
+
+	scaler := &scalerv1.VolumeScaler{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "vs1"},
+		Spec:       scalerv1.VolumeScalerSpec{PVCName: "src"},
+		Status: scalerv1.VolumeScalerStatus{
+			Migration: &scalerv1.VolumeScalerMigrationStatus{
+				Phase:        string(migration.PhaseSwapping),
+				NewPVCName:   "src-migrated",
+				WorkloadKind: "Deployment",
+				WorkloadName: "app",
+				Replicas:     3,
+			},
+		},
+	}
+
+	if err := c.advanceMigration(ctx, "ns", "src", "vs1", scaler); err != nil {
+		t.Fatalf("advanceMigration (Swapping): %v", err)
+	}
+
+	dep, err := c.kubeClient.AppsV1().Deployments("ns").Get(ctx, "app", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("getting Deployment: %v", err)
+	}
+	if got := *dep.Spec.Replicas; got != 3 {
+		t.Errorf("replicas after swap = %d, want 3 (resumed)", got)
+	}
+	if got := dep.Spec.Template.Spec.Volumes[0].PersistentVolumeClaim.ClaimName; got != "src-migrated" {
+		t.Errorf("claimName after swap = %q, want src-migrated", got)
+	}
+
+	mig := readMigrationStatus(t, ctx, c, "ns", "vs1")
+	if mig.Phase != string(migration.PhaseRepointing) {
+		t.Fatalf("phase after successful swap = %q, want %q", mig.Phase, migration.PhaseRepointing)
+	}
+
+	// Simulate the next sync picking up PhaseRepointing: Pause/Swap/Resume
+	// must not run again (the Deployment is already paused-then-resumed at
+	// 3 replicas and swapped; a second Pause/Resume would cycle replicas
+	// needlessly, the real-world outage this phase split prevents).
+	scaler.Status.Migration = mig
+	kubeClient := c.kubeClient.(*kubefake.Clientset)
+	actionsBefore := len(kubeClient.Actions())
+	if err := c.advanceMigration(ctx, "ns", "src", "vs1", scaler); err != nil {
+		t.Fatalf("advanceMigration (Repointing): %v", err)
+	}
+	if got := len(kubeClient.Actions()) - actionsBefore; got != 0 {
+		t.Errorf("Repointing made %d workload API calls, want 0 (Pause/Swap/Resume must not re-run)", got)
+	}
+
+	dep, err = c.kubeClient.AppsV1().Deployments("ns").Get(ctx, "app", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("getting Deployment: %v", err)
+	}
+	if got := *dep.Spec.Replicas; got != 3 {
+		t.Errorf("replicas after repoint = %d, want 3 (untouched)", got)
+	}
+
+	mig = readMigrationStatus(t, ctx, c, "ns", "vs1")
+	if mig.Phase != string(migration.PhaseCompleted) {
+		t.Errorf("phase after repoint = %q, want %q", mig.Phase, migration.PhaseCompleted)
+	}
+
+	u, err := c.dynClient.Resource(GVR).Namespace("ns").Get(ctx, "vs1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("getting VolumeScaler: %v", err)
+	}
+	pvcName, _, _ := unstructured.NestedString(u.Object, "spec", "pvcName")
+	if pvcName != "src-migrated" {
+		t.Errorf("spec.pvcName = %q, want src-migrated", pvcName)
+	}
+}
+
+func readMigrationStatus(t *testing.T, ctx context.Context, c *Controller, namespace, name string) *scalerv1.VolumeScalerMigrationStatus {
+	t.Helper()
+	u, err := c.dynClient.Resource(GVR).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("getting VolumeScaler: %v", err)
+	}
+	var vs scalerv1.VolumeScaler
+	if err := fromUnstructured(u, &vs); err != nil {
+		t.Fatalf("decoding VolumeScaler: %v", err)
+	}
+	if vs.Status.Migration == nil {
+		t.Fatal("status.migration is nil")
+	}
+	return vs.Status.Migration
+}
@@ -0,0 +1,109 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	scalerv1 "github.com/zghanem0/volumescaler/pkg/apis/volumescaler/v1"
+	"github.com/zghanem0/volumescaler/pkg/predictive"
+	"github.com/zghanem0/volumescaler/pkg/quantity"
+)
+
+// reconcilePredictive records the latest usage sample for scaler and
+// reports whether its growth-rate projection alone calls for a scale-up,
+// even though instantaneous utilization hasn't crossed threshold yet. It
+// persists the updated sample window (and, once a trend can be fit,
+// status.predictedFullAt) so a controller restart doesn't lose history
+// mid-window, but skips the status patch when nothing has actually
+// changed so its own writes don't re-trigger this reconcile.
+func (c *Controller) reconcilePredictive(ctx context.Context, namespace, scalerName string, scaler *scalerv1.VolumeScaler, threshold quantity.Policy, usedBytes, currentBytes int64) (shouldScale bool, err error) {
+	if scaler.Spec.Predictive == nil {
+		return false, nil
+	}
+	spec := scaler.Spec.Predictive
+
+	windowDuration, err := time.ParseDuration(spec.WindowDuration)
+	if err != nil {
+		return false, fmt.Errorf("parsing predictive.windowDuration of VolumeScaler %s/%s: %w", namespace, scalerName, err)
+	}
+	leadTime, err := time.ParseDuration(spec.LeadTime)
+	if err != nil {
+		return false, fmt.Errorf("parsing predictive.leadTime of VolumeScaler %s/%s: %w", namespace, scalerName, err)
+	}
+
+	now := time.Now()
+	window := c.predictive.Get(namespace+"/"+scalerName, toSamples(scaler.Status.Samples), now, windowDuration)
+	added := window.Add(now, usedBytes, windowDuration)
+
+	if projected, ok := window.Project(now, leadTime, spec.MinSamples); ok {
+		shouldScale = threshold.ShouldScale(projected, currentBytes)
+	}
+
+	var predictedFullAt string
+	if full, ok := window.FullAt(now, currentBytes, spec.MinSamples); ok {
+		predictedFullAt = full.UTC().Format(time.RFC3339)
+	}
+
+	if !added && predictedFullAt == scaler.Status.PredictedFullAt {
+		// Nothing new to persist. Skip the status patch: the VolumeScaler
+		// object is watched by vsInformer, so an unnecessary patch here
+		// would re-enqueue this key and feed a tight, self-sustaining
+		// reconcile loop.
+		return shouldScale, nil
+	}
+
+	if err := c.patchPredictiveStatus(ctx, namespace, scalerName, predictedFullAt, window.Samples()); err != nil {
+		return shouldScale, err
+	}
+	return shouldScale, nil
+}
+
+func (c *Controller) patchPredictiveStatus(ctx context.Context, namespace, scalerName, predictedFullAt string, samples []predictive.Sample) error {
+	body, err := json.Marshal(predictiveStatusPatch{Status: predictiveStatusFields{
+		PredictedFullAt: predictedFullAt,
+		Samples:         fromSamples(samples),
+	}})
+	if err != nil {
+		return fmt.Errorf("marshaling predictive status: %w", err)
+	}
+
+	_, err = c.dynClient.Resource(GVR).Namespace(namespace).Patch(ctx, scalerName, types.MergePatchType, body, metav1.PatchOptions{}, "status")
+	return err
+}
+
+type predictiveStatusFields struct {
+	PredictedFullAt string                      `json:"predictedFullAt"`
+	Samples         []scalerv1.PredictiveSample `json:"samples"`
+}
+
+type predictiveStatusPatch struct {
+	Status predictiveStatusFields `json:"status"`
+}
+
+func toSamples(in []scalerv1.PredictiveSample) []predictive.Sample {
+	out := make([]predictive.Sample, 0, len(in))
+	for _, s := range in {
+		t, err := time.Parse(time.RFC3339, s.Time)
+		if err != nil {
+			continue
+		}
+		out = append(out, predictive.Sample{Time: t, UsedBytes: s.UsedBytes})
+	}
+	return out
+}
+
+func fromSamples(in []predictive.Sample) []scalerv1.PredictiveSample {
+	out := make([]scalerv1.PredictiveSample, 0, len(in))
+	for _, s := range in {
+		out = append(out, scalerv1.PredictiveSample{
+			Time:      s.Time.UTC().Format(time.RFC3339),
+			UsedBytes: s.UsedBytes,
+		})
+	}
+	return out
+}
@@ -0,0 +1,265 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	scalerv1 "github.com/zghanem0/volumescaler/pkg/apis/volumescaler/v1"
+	"github.com/zghanem0/volumescaler/pkg/metrics"
+	"github.com/zghanem0/volumescaler/pkg/metrics/source"
+	"github.com/zghanem0/volumescaler/pkg/quantity"
+)
+
+// processNextWorkItem pops a single key off the queue and syncs it, treating
+// a conflict error as an expected race to retry rather than a scaling
+// failure, matching how the CSI external-resizer handles IsConflict.
+func (c *Controller) processNextWorkItem(ctx context.Context) bool {
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(key)
+
+	err := c.syncHandler(ctx, key.(string))
+	switch {
+	case err == nil:
+		c.queue.Forget(key)
+	case apierrors.IsConflict(err):
+		c.queue.AddRateLimited(key)
+	default:
+		klog.ErrorS(err, "sync failed", "key", key)
+		c.queue.AddRateLimited(key)
+	}
+	return true
+}
+
+// syncHandler reconciles a single "namespace/pvcName" key: it looks up the
+// matching VolumeScaler and PVC, measures current utilization through the
+// configured metrics source, and patches the PVC if the scaling policy calls
+// for it.
+func (c *Controller) syncHandler(ctx context.Context, key string) error {
+	namespace, pvcName, err := splitKey(key)
+	if err != nil {
+		return err
+	}
+
+	pvc, err := c.pvcLister.PersistentVolumeClaims(namespace).Get(pvcName)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	scaler, scalerName, err := c.findVolumeScaler(namespace, pvcName)
+	if err != nil {
+		return err
+	}
+	if scaler == nil {
+		return nil
+	}
+
+	if scaler.Status.ResizeInProgress {
+		// A previous scale-up is still being waited on; don't issue
+		// another one until it clears.
+		c.recorder.Eventf(volumeScalerRef(namespace, scalerName), corev1.EventTypeNormal, ReasonScalingSkippedCooldown,
+			"skipping scale check for PVC %s: a previous resize is still in progress", pvcName)
+		return nil
+	}
+
+	if handled, err := c.reconcileMigration(ctx, namespace, pvcName, scalerName, scaler, pvc); handled || err != nil {
+		return err
+	}
+
+	currentBytes := pvc.Spec.Resources.Requests.Storage().Value()
+
+	maxBytes, err := quantity.ParseBytes(scaler.Spec.MaxSize)
+	if err != nil {
+		c.recorder.Eventf(volumeScalerRef(namespace, scalerName), corev1.EventTypeWarning, ReasonInvalidSpec,
+			"invalid maxSize %q: %v", scaler.Spec.MaxSize, err)
+		return fmt.Errorf("parsing maxSize of VolumeScaler %s/%s: %w", namespace, scalerName, err)
+	}
+
+	metrics.CurrentCapacityBytes.WithLabelValues(namespace, pvcName).Set(float64(currentBytes))
+	metrics.MaxCapacityBytes.WithLabelValues(namespace, pvcName).Set(float64(maxBytes))
+
+	if currentBytes >= maxBytes {
+		return c.markReachedMaxSize(ctx, namespace, scalerName, scaler)
+	}
+
+	pod, err := c.findPodForPVC(namespace, pvcName)
+	if err != nil {
+		return err
+	}
+	if pod == nil || pod.Status.HostIP == "" {
+		// Nothing currently mounts this PVC; nothing to measure yet.
+		return nil
+	}
+
+	stats, err := c.metricsSource.Stats(ctx, source.Query{
+		Namespace: namespace,
+		PVCName:   pvcName,
+		NodeIP:    pod.Status.HostIP,
+		PodUID:    string(pod.UID),
+		PVCUID:    string(pvc.UID),
+	})
+	if err != nil {
+		return fmt.Errorf("fetching mount stats for PVC %s/%s: %w", namespace, pvcName, err)
+	}
+
+	if currentBytes > 0 {
+		metrics.CurrentUtilizationRatio.WithLabelValues(namespace, pvcName).Set(float64(stats.UsedBytes) / float64(currentBytes))
+	}
+
+	threshold, err := quantity.ParsePolicy(scaler.Spec.Threshold)
+	if err != nil {
+		c.recorder.Eventf(volumeScalerRef(namespace, scalerName), corev1.EventTypeWarning, ReasonInvalidSpec,
+			"invalid threshold %q: %v", scaler.Spec.Threshold, err)
+		return fmt.Errorf("parsing threshold of VolumeScaler %s/%s: %w", namespace, scalerName, err)
+	}
+	scale, err := quantity.ParsePolicy(scaler.Spec.Scale)
+	if err != nil {
+		c.recorder.Eventf(volumeScalerRef(namespace, scalerName), corev1.EventTypeWarning, ReasonInvalidSpec,
+			"invalid scale %q: %v", scaler.Spec.Scale, err)
+		return fmt.Errorf("parsing scale of VolumeScaler %s/%s: %w", namespace, scalerName, err)
+	}
+
+	predictiveScale, err := c.reconcilePredictive(ctx, namespace, scalerName, scaler, threshold, stats.UsedBytes, currentBytes)
+	if err != nil {
+		return err
+	}
+
+	reactiveScale := threshold.ShouldScale(stats.UsedBytes, currentBytes)
+	if !predictiveScale && !reactiveScale {
+		return nil
+	}
+
+	if reactiveScale {
+		metrics.ReactiveTriggered.WithLabelValues(namespace, scalerName).Inc()
+	} else {
+		metrics.PredictiveTriggered.WithLabelValues(namespace, scalerName).Inc()
+	}
+
+	newBytes := quantity.RoundUpBytes(currentBytes+scale.GrowBy(currentBytes), quantity.DefaultGranularity)
+	if newBytes > maxBytes {
+		newBytes = maxBytes
+	}
+	if newBytes <= currentBytes {
+		return nil
+	}
+
+	newSizeStr := resource.NewQuantity(newBytes, resource.BinarySI).String()
+	c.recorder.Eventf(volumeScalerRef(namespace, scalerName), corev1.EventTypeNormal, ReasonScalingVolume,
+		"scaling PVC %s to %s", pvcName, newSizeStr)
+	if err := c.scalePVC(ctx, namespace, pvcName, scalerName, scaler, newBytes, maxBytes); err != nil {
+		if !apierrors.IsConflict(err) {
+			c.recorder.Eventf(volumeScalerRef(namespace, scalerName), corev1.EventTypeWarning, ReasonScalingFailed,
+				"failed to scale PVC %s to %s: %v", pvcName, newSizeStr, err)
+		}
+		return err
+	}
+	return nil
+}
+
+// scalePVC patches the PVC to newSize, marks the VolumeScaler as
+// resizeInProgress, and hands off to watchResize to observe completion in
+// the background so the workqueue worker isn't blocked for the duration of
+// the resize.
+func (c *Controller) scalePVC(ctx context.Context, namespace, pvcName, scalerName string, scaler *scalerv1.VolumeScaler, newBytes, maxBytes int64) error {
+	newSizeStr := resource.NewQuantity(newBytes, resource.BinarySI).String()
+	patchedAt := time.Now()
+	pvcPatch := []byte(fmt.Sprintf(`{"spec":{"resources":{"requests":{"storage":"%s"}}}}`, newSizeStr))
+	if _, err := c.kubeClient.CoreV1().PersistentVolumeClaims(namespace).Patch(ctx, pvcName, types.MergePatchType, pvcPatch, metav1.PatchOptions{}); err != nil {
+		return fmt.Errorf("patching PVC %s/%s to %s: %w", namespace, pvcName, newSizeStr, err)
+	}
+	klog.InfoS("scaled PVC", "namespace", namespace, "pvc", pvcName, "size", newSizeStr)
+
+	scaledAt := patchedAt.UTC().Format(time.RFC3339)
+	statusPatch := []byte(fmt.Sprintf(`{"status":{"scaledAt":%q,"resizeInProgress":true}}`, scaledAt))
+	if _, err := c.dynClient.Resource(GVR).Namespace(namespace).Patch(ctx, scalerName, types.MergePatchType, statusPatch, metav1.PatchOptions{}, "status"); err != nil {
+		return fmt.Errorf("patching VolumeScaler %s/%s scaledAt: %w", namespace, scalerName, err)
+	}
+
+	if maxBytes-newBytes <= quantity.DefaultGranularity && !scaler.Status.ReachedMaxSize {
+		if err := c.markReachedMaxSize(ctx, namespace, scalerName, scaler); err != nil {
+			return err
+		}
+	}
+
+	go c.watchResize(ctx, namespace, pvcName, scalerName, newSizeStr, patchedAt)
+	return nil
+}
+
+func (c *Controller) markReachedMaxSize(ctx context.Context, namespace, scalerName string, scaler *scalerv1.VolumeScaler) error {
+	if scaler.Status.ReachedMaxSize {
+		return nil
+	}
+	patch := []byte(`{"status":{"reachedMaxSize":true}}`)
+	if _, err := c.dynClient.Resource(GVR).Namespace(namespace).Patch(ctx, scalerName, types.MergePatchType, patch, metav1.PatchOptions{}, "status"); err != nil {
+		return fmt.Errorf("patching VolumeScaler %s/%s reachedMaxSize: %w", namespace, scalerName, err)
+	}
+	klog.InfoS("PVC reached maxSize", "namespace", namespace, "volumeScaler", scalerName)
+	c.recorder.Eventf(volumeScalerRef(namespace, scalerName), corev1.EventTypeNormal, ReasonReachedMaxSize,
+		"PVC backing this VolumeScaler has reached maxSize %s", scaler.Spec.MaxSize)
+	return nil
+}
+
+// findVolumeScaler returns the VolumeScaler in namespace targeting pvcName,
+// if any, by scanning the vsInformer's cache.
+func (c *Controller) findVolumeScaler(namespace, pvcName string) (*scalerv1.VolumeScaler, string, error) {
+	for _, obj := range c.vsInformer.GetStore().List() {
+		u, ok := obj.(*unstructured.Unstructured)
+		if !ok || u.GetNamespace() != namespace {
+			continue
+		}
+		var vs scalerv1.VolumeScaler
+		if err := fromUnstructured(u, &vs); err != nil {
+			return nil, "", fmt.Errorf("decoding VolumeScaler %s/%s: %w", namespace, u.GetName(), err)
+		}
+		if vs.Spec.PVCName == pvcName {
+			return &vs, u.GetName(), nil
+		}
+	}
+	return nil, "", nil
+}
+
+// findPodForPVC returns a pod in namespace that currently mounts pvcName, if
+// any is scheduled.
+func (c *Controller) findPodForPVC(namespace, pvcName string) (*corev1.Pod, error) {
+	pods, err := c.podLister.Pods(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("listing pods in namespace %s: %w", namespace, err)
+	}
+	for _, pod := range pods {
+		for _, vol := range pod.Spec.Volumes {
+			if vol.PersistentVolumeClaim != nil && vol.PersistentVolumeClaim.ClaimName == pvcName {
+				return pod, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+func fromUnstructured(u *unstructured.Unstructured, vs *scalerv1.VolumeScaler) error {
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, vs)
+}
+
+func splitKey(key string) (namespace, name string, err error) {
+	namespace, name, err = cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid resource key %q: %w", key, err)
+	}
+	return namespace, name, nil
+}
@@ -0,0 +1,101 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+
+	scalerv1 "github.com/zghanem0/volumescaler/pkg/apis/volumescaler/v1"
+	"github.com/zghanem0/volumescaler/pkg/metrics"
+	"github.com/zghanem0/volumescaler/pkg/resize"
+)
+
+// watchResize blocks (up to the controller's configured resize timeout)
+// until the PVC's observed capacity and conditions show the resize has
+// completed, then clears resizeInProgress on the VolumeScaler. It runs in
+// its own goroutine so a slow CSI/kubelet expansion doesn't tie up a
+// workqueue worker. resizeInProgress is cleared whether the wait completed
+// or timed out: a stuck resize must not permanently block syncHandler from
+// ever reconsidering this PVC.
+func (c *Controller) watchResize(ctx context.Context, namespace, pvcName, scalerName, wantSizeStr string, patchedAt time.Time) {
+	wantSize := resource.MustParse(wantSizeStr)
+
+	pvc, result, err := c.waiter.Wait(ctx, namespace, pvcName, wantSize)
+	if err != nil {
+		klog.ErrorS(err, "waiting for resize of PVC", "namespace", namespace, "pvc", pvcName)
+		metrics.ResizeTotal.WithLabelValues(namespace, pvcName, "error").Inc()
+		return
+	}
+
+	if result.Completed {
+		metrics.ResizeDuration.WithLabelValues(namespace, pvcName).Observe(time.Since(patchedAt).Seconds())
+		metrics.ResizeTotal.WithLabelValues(namespace, pvcName, "success").Inc()
+		c.recorder.Eventf(volumeScalerRef(namespace, scalerName), corev1.EventTypeNormal, ReasonScaledVolume,
+			"PVC %s resized to %s", pvcName, wantSizeStr)
+	} else {
+		klog.InfoS("timed out waiting for PVC to reach requested size", "namespace", namespace, "pvc", pvcName, "wantSize", wantSizeStr)
+		metrics.ResizeTotal.WithLabelValues(namespace, pvcName, "timeout").Inc()
+		if pvc != nil {
+			c.recorder.Eventf(pvc, corev1.EventTypeWarning, "VolumeResizeTimeout",
+				"timed out waiting for PVC to reach %s", wantSizeStr)
+		}
+		// The resize may still complete later; requeue so a future sync
+		// re-evaluates once it does (or retries the scale-up otherwise).
+		c.queue.AddRateLimited(namespace + "/" + pvcName)
+	}
+
+	if err := c.updateResizeStatus(ctx, namespace, scalerName, pvc, result); err != nil {
+		klog.ErrorS(err, "recording resize status for VolumeScaler", "namespace", namespace, "volumeScaler", scalerName)
+	}
+}
+
+// updateResizeStatus records the PVC's last observed capacity and
+// resize-related conditions on the VolumeScaler, and always clears
+// resizeInProgress, regardless of whether the wait completed or timed out.
+// A timeout does not mean the resize is abandoned (the CSI driver may still
+// finish it later), but leaving resizeInProgress set would permanently stop
+// syncHandler from reconsidering this PVC, since nothing ever re-arms the
+// wait. Clearing it lets the next sync's reactive (and predictive) checks
+// decide on their own whether another resize is still warranted.
+func (c *Controller) updateResizeStatus(ctx context.Context, namespace, scalerName string, pvc *corev1.PersistentVolumeClaim, result resize.Result) error {
+	conditions := make([]scalerv1.VolumeScalerCondition, 0, len(pvc.Status.Conditions))
+	for _, cond := range pvc.Status.Conditions {
+		conditions = append(conditions, scalerv1.VolumeScalerCondition{
+			Type:               string(cond.Type),
+			Status:             cond.Status,
+			LastProbeTime:      cond.LastProbeTime,
+			LastTransitionTime: cond.LastTransitionTime,
+			Reason:             cond.Reason,
+			Message:            cond.Message,
+		})
+	}
+
+	body, err := json.Marshal(resizeStatusPatch{Status: resizeStatusFields{
+		ResizeInProgress:     false,
+		LastObservedCapacity: result.Capacity.String(),
+		Conditions:           conditions,
+	}})
+	if err != nil {
+		return fmt.Errorf("marshaling resize status: %w", err)
+	}
+
+	_, err = c.dynClient.Resource(GVR).Namespace(namespace).Patch(ctx, scalerName, types.MergePatchType, body, metav1.PatchOptions{}, "status")
+	return err
+}
+
+type resizeStatusFields struct {
+	ResizeInProgress     bool                             `json:"resizeInProgress"`
+	LastObservedCapacity string                           `json:"lastObservedCapacity"`
+	Conditions           []scalerv1.VolumeScalerCondition `json:"conditions"`
+}
+
+type resizeStatusPatch struct {
+	Status resizeStatusFields `json:"status"`
+}
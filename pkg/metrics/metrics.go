@@ -0,0 +1,60 @@
+// Package metrics holds the controller's Prometheus instrumentation.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// PredictiveTriggered counts scale-ups triggered by the predictive
+// growth-rate projection crossing Threshold ahead of instantaneous
+// utilization doing so, per VolumeScaler.
+var PredictiveTriggered = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "volumescaler_predictive_triggered_total",
+	Help: "Number of scale-ups triggered by the predictive growth-rate projection.",
+}, []string{"namespace", "volumescaler"})
+
+// ReactiveTriggered counts scale-ups triggered by instantaneous utilization
+// crossing Threshold, per VolumeScaler.
+var ReactiveTriggered = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "volumescaler_reactive_triggered_total",
+	Help: "Number of scale-ups triggered by instantaneous utilization crossing the threshold.",
+}, []string{"namespace", "volumescaler"})
+
+// ResizeTotal counts completed PVC resizes, by outcome: "success", the
+// waiter timing out, or the waiter itself erroring.
+var ResizeTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "volumescaler_resize_total",
+	Help: "Number of PVC resizes, by namespace, PVC and result.",
+}, []string{"namespace", "pvc", "result"})
+
+// CurrentUtilizationRatio is the last-observed ratio of a PVC's used bytes,
+// as measured by the configured metrics source, to its requested storage
+// (spec.resources.requests.storage).
+var CurrentUtilizationRatio = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "volumescaler_current_utilization_ratio",
+	Help: "Last-observed fraction of a PVC's capacity in use.",
+}, []string{"namespace", "pvc"})
+
+// CurrentCapacityBytes is a PVC's current spec.resources.requests.storage,
+// in bytes.
+var CurrentCapacityBytes = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "volumescaler_current_capacity_bytes",
+	Help: "A PVC's current requested storage capacity, in bytes.",
+}, []string{"namespace", "pvc"})
+
+// MaxCapacityBytes is the maxSize a VolumeScaler will grow its PVC to, in
+// bytes.
+var MaxCapacityBytes = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "volumescaler_max_capacity_bytes",
+	Help: "The maxSize a VolumeScaler will grow its PVC to, in bytes.",
+}, []string{"namespace", "pvc"})
+
+// ResizeDuration observes how long a resize took from patch submission
+// until the waiter observed the PVC's status.capacity reflecting the new
+// size.
+var ResizeDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "volumescaler_resize_duration_seconds",
+	Help:    "Time from patching a PVC's requested size until status.capacity reflects it.",
+	Buckets: prometheus.DefBuckets,
+}, []string{"namespace", "pvc"})
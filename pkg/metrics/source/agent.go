@@ -0,0 +1,29 @@
+package source
+
+import (
+	"context"
+
+	"github.com/zghanem0/volumescaler/pkg/agent"
+)
+
+// AgentSource wraps the original DaemonSet-based volumescaler-agent,
+// kept as a fallback for clusters where the controller can't reach
+// kubelet's stats API directly and doesn't run Prometheus.
+type AgentSource struct {
+	client *agent.Client
+}
+
+// NewAgentSource returns an AgentSource querying node-local agents on port,
+// authenticating with token.
+func NewAgentSource(port int, token string) *AgentSource {
+	return &AgentSource{client: agent.NewClient(port, token)}
+}
+
+// Stats implements Source.
+func (s *AgentSource) Stats(ctx context.Context, q Query) (Stats, error) {
+	stats, err := s.client.Stats(q.NodeIP, q.PodUID, q.PVCUID)
+	if err != nil {
+		return Stats{}, err
+	}
+	return Stats{UsedBytes: stats.UsedBytes, CapacityBytes: stats.CapacityBytes}, nil
+}
@@ -0,0 +1,91 @@
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PrometheusSource measures PVC usage by querying a Prometheus server for
+// the kubelet_volume_stats_used_bytes / kubelet_volume_stats_capacity_bytes
+// series kubelet already exports on its /metrics/resource endpoint, rather
+// than talking to any one node directly.
+type PrometheusSource struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewPrometheusSource returns a PrometheusSource querying the Prometheus
+// server at baseURL (e.g. "http://prometheus.monitoring:9090").
+func NewPrometheusSource(baseURL string) *PrometheusSource {
+	return &PrometheusSource{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Stats implements Source.
+func (s *PrometheusSource) Stats(ctx context.Context, q Query) (Stats, error) {
+	used, err := s.scalar(ctx, fmt.Sprintf(`kubelet_volume_stats_used_bytes{namespace=%q,persistentvolumeclaim=%q}`, q.Namespace, q.PVCName))
+	if err != nil {
+		return Stats{}, fmt.Errorf("querying used bytes for PVC %s/%s: %w", q.Namespace, q.PVCName, err)
+	}
+	capacity, err := s.scalar(ctx, fmt.Sprintf(`kubelet_volume_stats_capacity_bytes{namespace=%q,persistentvolumeclaim=%q}`, q.Namespace, q.PVCName))
+	if err != nil {
+		return Stats{}, fmt.Errorf("querying capacity bytes for PVC %s/%s: %w", q.Namespace, q.PVCName, err)
+	}
+	return Stats{UsedBytes: int64(used), CapacityBytes: int64(capacity)}, nil
+}
+
+// scalar runs an instant PromQL query and returns the single expected
+// sample's value.
+func (s *PrometheusSource) scalar(ctx context.Context, query string) (float64, error) {
+	reqURL := fmt.Sprintf("%s/api/v1/query?query=%s", s.baseURL, url.QueryEscape(query))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return 0, fmt.Errorf("building Prometheus query request: %w", err)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("querying Prometheus at %s: %w", s.baseURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("Prometheus at %s returned status %d", s.baseURL, resp.StatusCode)
+	}
+
+	var result struct {
+		Status string `json:"status"`
+		Data   struct {
+			Result []struct {
+				Value []interface{} `json:"value"`
+			} `json:"result"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return 0, fmt.Errorf("decoding Prometheus response from %s: %w", s.baseURL, err)
+	}
+	if result.Status != "success" || len(result.Data.Result) == 0 {
+		return 0, fmt.Errorf("query %q returned no data", query)
+	}
+
+	sample := result.Data.Result[0].Value
+	if len(sample) != 2 {
+		return 0, fmt.Errorf("query %q returned a malformed sample", query)
+	}
+	valStr, ok := sample[1].(string)
+	if !ok {
+		return 0, fmt.Errorf("query %q returned a non-string sample value", query)
+	}
+	value, err := strconv.ParseFloat(valStr, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parsing sample value %q: %w", valStr, err)
+	}
+	return value, nil
+}
@@ -0,0 +1,56 @@
+// Package source provides pluggable ways to measure how much of a PVC is
+// in use. The controller no longer needs a privileged node-local agent to
+// answer this: it can read kubelet's own /stats/summary endpoint, query
+// Prometheus for the same metrics kubelet already exports, or fall back to
+// the original DaemonSet-based agent for clusters that run neither.
+package source
+
+import (
+	"context"
+	"fmt"
+)
+
+// Stats reports the observed usage of a single PVC mount.
+type Stats struct {
+	UsedBytes     int64
+	CapacityBytes int64
+}
+
+// Query identifies the PVC whose usage should be measured, and the pod
+// currently mounting it. Node-local sources need NodeIP/PodUID/PVCUID to
+// find the right mount; cluster-wide sources only need Namespace/PVCName.
+type Query struct {
+	Namespace string
+	PVCName   string
+	NodeIP    string
+	PodUID    string
+	PVCUID    string
+}
+
+// Source measures the usage of the PVC described by q.
+type Source interface {
+	Stats(ctx context.Context, q Query) (Stats, error)
+}
+
+// New constructs the Source selected by kind ("kubelet", "prometheus", or
+// "agent"), reading whichever of the remaining arguments that kind needs.
+// kind defaults to "kubelet" when empty. agentToken authenticates to the
+// node-local agent and is required when kind is "agent".
+func New(kind string, kubeletPort int, prometheusURL string, agentPort int, agentToken string) (Source, error) {
+	switch kind {
+	case "", "kubelet":
+		return NewKubeletStatsSource(kubeletPort)
+	case "prometheus":
+		if prometheusURL == "" {
+			return nil, fmt.Errorf("--prometheus-url is required when --metrics-source=prometheus")
+		}
+		return NewPrometheusSource(prometheusURL), nil
+	case "agent":
+		if agentToken == "" {
+			return nil, fmt.Errorf("--agent-token-file is required when --metrics-source=agent")
+		}
+		return NewAgentSource(agentPort, agentToken), nil
+	default:
+		return nil, fmt.Errorf("unknown metrics source %q (want kubelet, prometheus, or agent)", kind)
+	}
+}
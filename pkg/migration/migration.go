@@ -0,0 +1,297 @@
+// Package migration orchestrates moving a PVC to a different StorageClass
+// once VolumeScaler can no longer grow it within its current one: snapshot
+// the source PVC, provision a new PVC from that snapshot in the target
+// StorageClass, pause the owning workload, swap the PVC reference in its
+// pod template, and resume it.
+package migration
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+// SnapshotGVR identifies the external-snapshotter's VolumeSnapshot CRD.
+var SnapshotGVR = schema.GroupVersionResource{
+	Group:    "snapshot.storage.k8s.io",
+	Version:  "v1",
+	Resource: "volumesnapshots",
+}
+
+// Phase is a step of a StorageClass migration: Snapshotting ->
+// Provisioning -> Swapping -> Repointing -> Completed, or Failed (with
+// rollback of anything created so far) at any step up through Swapping.
+type Phase string
+
+const (
+	PhaseSnapshotting Phase = "Snapshotting"
+	PhaseProvisioning Phase = "Provisioning"
+	PhaseSwapping     Phase = "Swapping"
+	// PhaseRepointing is entered once Pause/Swap/Resume have all succeeded,
+	// and covers only the final repoint of the VolumeScaler's spec.pvcName.
+	// Keeping it distinct from PhaseSwapping means a failure that occurs
+	// only in the repoint step retries the repoint alone on the next sync,
+	// instead of re-running Pause/Swap/Resume against a workload that's
+	// already fully migrated.
+	PhaseRepointing Phase = "Repointing"
+	PhaseCompleted  Phase = "Completed"
+	PhaseFailed     Phase = "Failed"
+)
+
+// Plan describes a single migration of SourcePVCName to TargetStorageClass.
+type Plan struct {
+	Namespace          string
+	SourcePVCName      string
+	TargetStorageClass string
+	SnapshotClassName  string
+}
+
+// Migrator runs the individual steps of a migration Plan against the
+// cluster. Each step is meant to be called once per controller sync of the
+// owning VolumeScaler, with progress tracked on its status so a migration
+// survives controller restarts.
+type Migrator struct {
+	kubeClient kubernetes.Interface
+	dynClient  dynamic.Interface
+}
+
+// NewMigrator returns a Migrator backed by the given clients.
+func NewMigrator(kubeClient kubernetes.Interface, dynClient dynamic.Interface) *Migrator {
+	return &Migrator{kubeClient: kubeClient, dynClient: dynClient}
+}
+
+func snapshotName(sourcePVCName string) string {
+	return sourcePVCName + "-migration"
+}
+
+func migratedPVCName(sourcePVCName string) string {
+	return sourcePVCName + "-migrated"
+}
+
+// Snapshot creates a VolumeSnapshot of the source PVC (or reuses one from a
+// prior, interrupted attempt) and returns its name.
+func (m *Migrator) Snapshot(ctx context.Context, plan Plan) (string, error) {
+	name := snapshotName(plan.SourcePVCName)
+	snap := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "snapshot.storage.k8s.io/v1",
+		"kind":       "VolumeSnapshot",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": plan.Namespace,
+		},
+		"spec": map[string]interface{}{
+			"volumeSnapshotClassName": plan.SnapshotClassName,
+			"source": map[string]interface{}{
+				"persistentVolumeClaimName": plan.SourcePVCName,
+			},
+		},
+	}}
+
+	_, err := m.dynClient.Resource(SnapshotGVR).Namespace(plan.Namespace).Create(ctx, snap, metav1.CreateOptions{})
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return "", fmt.Errorf("creating VolumeSnapshot %s/%s: %w", plan.Namespace, name, err)
+	}
+	return name, nil
+}
+
+// SnapshotReady reports whether the named VolumeSnapshot has finished.
+func (m *Migrator) SnapshotReady(ctx context.Context, namespace, name string) (bool, error) {
+	u, err := m.dynClient.Resource(SnapshotGVR).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return false, fmt.Errorf("getting VolumeSnapshot %s/%s: %w", namespace, name, err)
+	}
+	ready, found, err := unstructured.NestedBool(u.Object, "status", "readyToUse")
+	if err != nil || !found {
+		return false, nil
+	}
+	return ready, nil
+}
+
+// ProvisionPVC creates a new PVC in plan.TargetStorageClass sourced from
+// the given VolumeSnapshot, sized to match the source PVC, and returns its
+// name.
+func (m *Migrator) ProvisionPVC(ctx context.Context, plan Plan, snapName string, size resource.Quantity) (string, error) {
+	name := migratedPVCName(plan.SourcePVCName)
+	apiGroup := SnapshotGVR.Group
+	storageClass := plan.TargetStorageClass
+
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: plan.Namespace},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			StorageClassName: &storageClass,
+			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: size},
+			},
+			DataSource: &corev1.TypedLocalObjectReference{
+				APIGroup: &apiGroup,
+				Kind:     "VolumeSnapshot",
+				Name:     snapName,
+			},
+		},
+	}
+
+	_, err := m.kubeClient.CoreV1().PersistentVolumeClaims(plan.Namespace).Create(ctx, pvc, metav1.CreateOptions{})
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return "", fmt.Errorf("creating migrated PVC %s/%s: %w", plan.Namespace, name, err)
+	}
+	return name, nil
+}
+
+// PauseWorkload scales the named Deployment or StatefulSet to zero
+// replicas so its pods release the PVC being migrated. It is a no-op if
+// the workload is already at zero replicas, so a retry after a partially
+// completed swap doesn't re-patch it needlessly.
+func (m *Migrator) PauseWorkload(ctx context.Context, namespace, kind, name string) error {
+	current, err := m.currentReplicas(ctx, namespace, kind, name)
+	if err != nil {
+		return err
+	}
+	if current == 0 {
+		return nil
+	}
+	return m.scaleWorkload(ctx, namespace, kind, name, 0)
+}
+
+// ResumeWorkload restores the named Deployment or StatefulSet to replicas.
+// It is a no-op if the workload is already at replicas, so it can be
+// safely retried after a prior attempt failed partway (e.g. on a
+// resourceVersion conflict).
+func (m *Migrator) ResumeWorkload(ctx context.Context, namespace, kind, name string, replicas int32) error {
+	current, err := m.currentReplicas(ctx, namespace, kind, name)
+	if err != nil {
+		return err
+	}
+	if current == replicas {
+		return nil
+	}
+	return m.scaleWorkload(ctx, namespace, kind, name, replicas)
+}
+
+func (m *Migrator) currentReplicas(ctx context.Context, namespace, kind, name string) (int32, error) {
+	switch kind {
+	case "Deployment":
+		dep, err := m.kubeClient.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return 0, fmt.Errorf("getting Deployment %s/%s: %w", namespace, name, err)
+		}
+		return replicasOrDefault(dep.Spec.Replicas), nil
+	case "StatefulSet":
+		sts, err := m.kubeClient.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return 0, fmt.Errorf("getting StatefulSet %s/%s: %w", namespace, name, err)
+		}
+		return replicasOrDefault(sts.Spec.Replicas), nil
+	default:
+		return 0, fmt.Errorf("unsupported workload kind %q", kind)
+	}
+}
+
+func replicasOrDefault(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
+
+func (m *Migrator) scaleWorkload(ctx context.Context, namespace, kind, name string, replicas int32) error {
+	patch := []byte(fmt.Sprintf(`{"spec":{"replicas":%d}}`, replicas))
+	var err error
+	switch kind {
+	case "Deployment":
+		_, err = m.kubeClient.AppsV1().Deployments(namespace).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+	case "StatefulSet":
+		_, err = m.kubeClient.AppsV1().StatefulSets(namespace).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+	default:
+		return fmt.Errorf("unsupported workload kind %q", kind)
+	}
+	if err != nil {
+		return fmt.Errorf("scaling %s %s/%s to %d replicas: %w", kind, namespace, name, replicas, err)
+	}
+	return nil
+}
+
+// SwapPVC replaces oldPVCName with newPVCName in the named workload's pod
+// template volumes. It is a no-op if the pod template already references
+// newPVCName, so it can be safely retried after a prior attempt updated
+// the workload but failed to report success.
+func (m *Migrator) SwapPVC(ctx context.Context, namespace, kind, name, oldPVCName, newPVCName string) error {
+	switch kind {
+	case "Deployment":
+		dep, err := m.kubeClient.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("getting Deployment %s/%s: %w", namespace, name, err)
+		}
+		if volumeReferencesClaim(dep.Spec.Template.Spec.Volumes, newPVCName) {
+			return nil
+		}
+		if !swapVolumeClaim(dep.Spec.Template.Spec.Volumes, oldPVCName, newPVCName) {
+			return fmt.Errorf("no volume referencing PVC %s found in Deployment %s/%s", oldPVCName, namespace, name)
+		}
+		if _, err := m.kubeClient.AppsV1().Deployments(namespace).Update(ctx, dep, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("updating Deployment %s/%s: %w", namespace, name, err)
+		}
+	case "StatefulSet":
+		sts, err := m.kubeClient.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("getting StatefulSet %s/%s: %w", namespace, name, err)
+		}
+		if volumeReferencesClaim(sts.Spec.Template.Spec.Volumes, newPVCName) {
+			return nil
+		}
+		if !swapVolumeClaim(sts.Spec.Template.Spec.Volumes, oldPVCName, newPVCName) {
+			return fmt.Errorf("no volume referencing PVC %s found in StatefulSet %s/%s", oldPVCName, namespace, name)
+		}
+		if _, err := m.kubeClient.AppsV1().StatefulSets(namespace).Update(ctx, sts, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("updating StatefulSet %s/%s: %w", namespace, name, err)
+		}
+	default:
+		return fmt.Errorf("unsupported workload kind %q", kind)
+	}
+	return nil
+}
+
+func volumeReferencesClaim(volumes []corev1.Volume, pvcName string) bool {
+	for _, vol := range volumes {
+		if vol.PersistentVolumeClaim != nil && vol.PersistentVolumeClaim.ClaimName == pvcName {
+			return true
+		}
+	}
+	return false
+}
+
+func swapVolumeClaim(volumes []corev1.Volume, oldPVCName, newPVCName string) bool {
+	swapped := false
+	for i, vol := range volumes {
+		if vol.PersistentVolumeClaim != nil && vol.PersistentVolumeClaim.ClaimName == oldPVCName {
+			volumes[i].PersistentVolumeClaim.ClaimName = newPVCName
+			swapped = true
+		}
+	}
+	return swapped
+}
+
+// Rollback removes whatever was created so far for a failed migration, so
+// the workload is left pointing at the original PVC.
+func (m *Migrator) Rollback(ctx context.Context, namespace, snapName, newPVCName string) {
+	if newPVCName != "" {
+		if err := m.kubeClient.CoreV1().PersistentVolumeClaims(namespace).Delete(ctx, newPVCName, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			klog.ErrorS(err, "migration: rollback: deleting PVC", "namespace", namespace, "pvc", newPVCName)
+		}
+	}
+	if snapName != "" {
+		if err := m.dynClient.Resource(SnapshotGVR).Namespace(namespace).Delete(ctx, snapName, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			klog.ErrorS(err, "migration: rollback: deleting VolumeSnapshot", "namespace", namespace, "snapshot", snapName)
+		}
+	}
+}
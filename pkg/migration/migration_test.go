@@ -0,0 +1,139 @@
+package migration
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func int32ptr(i int32) *int32 { return &i }
+
+func deployment(name string, replicas int32, pvcName string) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns"},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: int32ptr(replicas),
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Volumes: []corev1.Volume{{
+						Name: "data",
+						VolumeSource: corev1.VolumeSource{
+							PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: pvcName},
+						},
+					}},
+				},
+			},
+		},
+	}
+}
+
+func TestPauseWorkloadIdempotent(t *testing.T) {
+	cases := []struct {
+		name     string
+		replicas int32
+	}{
+		{"already paused", 0},
+		{"needs pausing", 3},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset(deployment("app", tc.replicas, "src"))
+			m := NewMigrator(client, nil)
+
+			if err := m.PauseWorkload(context.Background(), "ns", "Deployment", "app"); err != nil {
+				t.Fatalf("PauseWorkload: %v", err)
+			}
+			dep, err := client.AppsV1().Deployments("ns").Get(context.Background(), "app", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("getting Deployment: %v", err)
+			}
+			if got := *dep.Spec.Replicas; got != 0 {
+				t.Errorf("replicas = %d, want 0", got)
+			}
+
+			// Calling again once already paused must be a no-op, not error.
+			if err := m.PauseWorkload(context.Background(), "ns", "Deployment", "app"); err != nil {
+				t.Fatalf("PauseWorkload (retry): %v", err)
+			}
+		})
+	}
+}
+
+func TestResumeWorkloadIdempotent(t *testing.T) {
+	cases := []struct {
+		name     string
+		replicas int32
+	}{
+		{"already resumed", 3},
+		{"needs resuming", 0},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset(deployment("app", tc.replicas, "src"))
+			m := NewMigrator(client, nil)
+
+			if err := m.ResumeWorkload(context.Background(), "ns", "Deployment", "app", 3); err != nil {
+				t.Fatalf("ResumeWorkload: %v", err)
+			}
+			dep, err := client.AppsV1().Deployments("ns").Get(context.Background(), "app", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("getting Deployment: %v", err)
+			}
+			if got := *dep.Spec.Replicas; got != 3 {
+				t.Errorf("replicas = %d, want 3", got)
+			}
+
+			// Calling again once already resumed must be a no-op, not error.
+			if err := m.ResumeWorkload(context.Background(), "ns", "Deployment", "app", 3); err != nil {
+				t.Fatalf("ResumeWorkload (retry): %v", err)
+			}
+		})
+	}
+}
+
+func TestSwapPVCIdempotent(t *testing.T) {
+	t.Run("already swapped", func(t *testing.T) {
+		client := fake.NewSimpleClientset(deployment("app", 0, "src-migrated"))
+		m := NewMigrator(client, nil)
+
+		if err := m.SwapPVC(context.Background(), "ns", "Deployment", "app", "src", "src-migrated"); err != nil {
+			t.Fatalf("SwapPVC: %v", err)
+		}
+		dep, err := client.AppsV1().Deployments("ns").Get(context.Background(), "app", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("getting Deployment: %v", err)
+		}
+		if got := dep.Spec.Template.Spec.Volumes[0].PersistentVolumeClaim.ClaimName; got != "src-migrated" {
+			t.Errorf("claimName = %q, want src-migrated", got)
+		}
+	})
+
+	t.Run("needs swapping", func(t *testing.T) {
+		client := fake.NewSimpleClientset(deployment("app", 0, "src"))
+		m := NewMigrator(client, nil)
+
+		if err := m.SwapPVC(context.Background(), "ns", "Deployment", "app", "src", "src-migrated"); err != nil {
+			t.Fatalf("SwapPVC: %v", err)
+		}
+		dep, err := client.AppsV1().Deployments("ns").Get(context.Background(), "app", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("getting Deployment: %v", err)
+		}
+		if got := dep.Spec.Template.Spec.Volumes[0].PersistentVolumeClaim.ClaimName; got != "src-migrated" {
+			t.Errorf("claimName = %q, want src-migrated", got)
+		}
+	})
+
+	t.Run("no matching volume", func(t *testing.T) {
+		client := fake.NewSimpleClientset(deployment("app", 0, "other"))
+		m := NewMigrator(client, nil)
+
+		if err := m.SwapPVC(context.Background(), "ns", "Deployment", "app", "src", "src-migrated"); err == nil {
+			t.Fatal("SwapPVC returned no error, want one")
+		}
+	})
+}
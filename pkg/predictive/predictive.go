@@ -0,0 +1,160 @@
+// Package predictive projects when a PVC will fill up by fitting a linear
+// regression over its recent usage samples, so VolumeScaler can trigger a
+// scale-up ahead of utilization actually crossing Threshold instead of only
+// reacting once it has.
+package predictive
+
+import (
+	"sync"
+	"time"
+)
+
+// Sample is a single (timestamp, usedBytes) observation.
+type Sample struct {
+	Time      time.Time
+	UsedBytes int64
+}
+
+// Window holds the in-window usage samples for a single PVC, bounded to a
+// rolling duration, used to fit a linear trend of usage over time.
+type Window struct {
+	samples []Sample
+}
+
+// NewWindow returns a Window seeded with previously-persisted samples (e.g.
+// from status.samples, after a controller restart), trimmed to
+// windowDuration as of now.
+func NewWindow(seed []Sample, now time.Time, windowDuration time.Duration) *Window {
+	w := &Window{samples: append([]Sample(nil), seed...)}
+	w.trim(now, windowDuration)
+	return w
+}
+
+// MinSampleInterval is the minimum gap enforced between consecutive
+// samples in a Window. It guards against a reconcile that was itself
+// triggered by the controller's own status patch (rather than a fresh
+// measurement) appending a near-duplicate sample, which would otherwise
+// patch status again and feed a self-sustaining reconcile loop.
+const MinSampleInterval = 30 * time.Second
+
+// Add records a new sample, unless one was already recorded within
+// MinSampleInterval of now, and trims anything that has fallen out of
+// windowDuration. It reports whether a sample was appended.
+func (w *Window) Add(now time.Time, usedBytes int64, windowDuration time.Duration) bool {
+	if n := len(w.samples); n > 0 && now.Sub(w.samples[n-1].Time) < MinSampleInterval {
+		w.trim(now, windowDuration)
+		return false
+	}
+	w.samples = append(w.samples, Sample{Time: now, UsedBytes: usedBytes})
+	w.trim(now, windowDuration)
+	return true
+}
+
+func (w *Window) trim(now time.Time, windowDuration time.Duration) {
+	cutoff := now.Add(-windowDuration)
+	i := 0
+	for ; i < len(w.samples); i++ {
+		if !w.samples[i].Time.Before(cutoff) {
+			break
+		}
+	}
+	w.samples = w.samples[i:]
+}
+
+// Samples returns the current in-window samples, oldest first.
+func (w *Window) Samples() []Sample {
+	return w.samples
+}
+
+// fit computes the least-squares linear regression of usedBytes against
+// time, y = a + b*t, with t measured in seconds relative to now (so a is
+// the usage at now). It requires at least minSamples points spanning at
+// least two distinct timestamps.
+func (w *Window) fit(now time.Time, minSamples int) (slope, intercept float64, ok bool) {
+	if minSamples < 2 {
+		minSamples = 2
+	}
+	if len(w.samples) < minSamples {
+		return 0, 0, false
+	}
+
+	n := float64(len(w.samples))
+	var sumT, sumY float64
+	for _, s := range w.samples {
+		sumT += s.Time.Sub(now).Seconds()
+		sumY += float64(s.UsedBytes)
+	}
+	meanT := sumT / n
+	meanY := sumY / n
+
+	var num, den float64
+	for _, s := range w.samples {
+		t := s.Time.Sub(now).Seconds()
+		y := float64(s.UsedBytes)
+		num += (t - meanT) * (y - meanY)
+		den += (t - meanT) * (t - meanT)
+	}
+	if den == 0 {
+		return 0, 0, false
+	}
+
+	slope = num / den
+	intercept = meanY - slope*meanT
+	return slope, intercept, true
+}
+
+// Project returns the usage projected at now+leadTime, fitting the window's
+// current trend. ok is false when there aren't yet minSamples distinct
+// points to fit.
+func (w *Window) Project(now time.Time, leadTime time.Duration, minSamples int) (usedBytes int64, ok bool) {
+	slope, intercept, ok := w.fit(now, minSamples)
+	if !ok {
+		return 0, false
+	}
+	v := intercept + slope*leadTime.Seconds()
+	if v < 0 {
+		v = 0
+	}
+	return int64(v), true
+}
+
+// FullAt returns the time at which the current trend projects usage to
+// reach capacityBytes. ok is false when there aren't yet minSamples
+// distinct points to fit, or the trend isn't growing.
+func (w *Window) FullAt(now time.Time, capacityBytes int64, minSamples int) (full time.Time, ok bool) {
+	slope, intercept, ok := w.fit(now, minSamples)
+	if !ok || slope <= 0 {
+		return time.Time{}, false
+	}
+	seconds := (float64(capacityBytes) - intercept) / slope
+	if seconds < 0 {
+		return time.Time{}, false
+	}
+	return now.Add(time.Duration(seconds * float64(time.Second))), true
+}
+
+// Store holds the in-memory Window for each VolumeScaler, keyed by
+// "namespace/name", so a window's history survives across syncs without
+// needing a ConfigMap.
+type Store struct {
+	mu      sync.Mutex
+	windows map[string]*Window
+}
+
+// NewStore returns an empty Store.
+func NewStore() *Store {
+	return &Store{windows: map[string]*Window{}}
+}
+
+// Get returns the Window for key, seeding it from seed the first time key
+// is seen (e.g. from status.samples, right after a controller restart).
+func (s *Store) Get(key string, seed []Sample, now time.Time, windowDuration time.Duration) *Window {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w, ok := s.windows[key]
+	if !ok {
+		w = NewWindow(seed, now, windowDuration)
+		s.windows[key] = w
+	}
+	return w
+}
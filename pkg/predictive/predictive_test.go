@@ -0,0 +1,126 @@
+package predictive
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWindowAddTrimsOutOfWindowSamples(t *testing.T) {
+	w := NewWindow(nil, time.Time{}, time.Hour)
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	w.Add(base, 1, time.Hour)
+	w.Add(base.Add(45*time.Minute), 2, time.Hour)
+	w.Add(base.Add(90*time.Minute), 3, time.Hour)
+
+	got := w.Samples()
+	if len(got) != 2 {
+		t.Fatalf("len(Samples()) = %d, want 2 (first sample should have aged out of the 1h window)", len(got))
+	}
+	if got[0].UsedBytes != 2 || got[1].UsedBytes != 3 {
+		t.Errorf("Samples() = %+v, want samples with UsedBytes 2 and 3", got)
+	}
+}
+
+func TestWindowAddSkipsWithinMinSampleInterval(t *testing.T) {
+	w := NewWindow(nil, time.Time{}, time.Hour)
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	if added := w.Add(base, 1, time.Hour); !added {
+		t.Fatal("Add() = false for first sample, want true")
+	}
+	if added := w.Add(base.Add(MinSampleInterval/2), 2, time.Hour); added {
+		t.Error("Add() = true for a sample within MinSampleInterval of the last, want false")
+	}
+	if len(w.Samples()) != 1 {
+		t.Fatalf("len(Samples()) = %d, want 1 (second sample should have been skipped)", len(w.Samples()))
+	}
+
+	if added := w.Add(base.Add(MinSampleInterval), 3, time.Hour); !added {
+		t.Error("Add() = false for a sample exactly MinSampleInterval after the last, want true")
+	}
+}
+
+func TestWindowProjectRequiresMinSamples(t *testing.T) {
+	w := NewWindow(nil, time.Time{}, time.Hour)
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	w.Add(base, 100, time.Hour)
+
+	if _, ok := w.Project(base, time.Minute, 2); ok {
+		t.Error("Project() ok = true with only one sample and minSamples=2, want false")
+	}
+}
+
+func TestWindowProjectGrowingTrend(t *testing.T) {
+	w := NewWindow(nil, time.Time{}, time.Hour)
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// Usage grows linearly at 1 byte/second.
+	for i := 0; i < 5; i++ {
+		w.Add(base.Add(time.Duration(i)*MinSampleInterval), int64(i)*int64(MinSampleInterval/time.Second), time.Hour)
+	}
+	now := base.Add(4 * MinSampleInterval)
+
+	projected, ok := w.Project(now, time.Minute, 2)
+	if !ok {
+		t.Fatal("Project() ok = false, want true with a clear growing trend")
+	}
+	want := int64(120 + 60) // ~120 bytes used at `now`, plus 1 byte/sec * 60s lead time
+	if diff := projected - want; diff < -1 || diff > 1 {
+		t.Errorf("Project() = %d, want approximately %d", projected, want)
+	}
+}
+
+func TestWindowFullAt(t *testing.T) {
+	w := NewWindow(nil, time.Time{}, time.Hour)
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 5; i++ {
+		w.Add(base.Add(time.Duration(i)*MinSampleInterval), int64(i)*int64(MinSampleInterval/time.Second), time.Hour)
+	}
+	now := base.Add(4 * MinSampleInterval)
+
+	full, ok := w.FullAt(now, 600, 2)
+	if !ok {
+		t.Fatal("FullAt() ok = false, want true with a clear growing trend")
+	}
+	wantSeconds := int64(600 - 120) // current projected usage at `now` is ~120 bytes
+	want := now.Add(time.Duration(wantSeconds) * time.Second)
+	if diff := full.Sub(want); diff < -time.Second || diff > time.Second {
+		t.Errorf("FullAt() = %v, want approximately %v", full, want)
+	}
+}
+
+func TestWindowFullAtFlatTrendNotOK(t *testing.T) {
+	w := NewWindow(nil, time.Time{}, time.Hour)
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 5; i++ {
+		w.Add(base.Add(time.Duration(i)*MinSampleInterval), 100, time.Hour)
+	}
+	now := base.Add(4 * MinSampleInterval)
+
+	if _, ok := w.FullAt(now, 1000, 2); ok {
+		t.Error("FullAt() ok = true for a flat (non-growing) trend, want false")
+	}
+}
+
+func TestStoreGetSeedsOnce(t *testing.T) {
+	s := NewStore()
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	seed := []Sample{{Time: base, UsedBytes: 42}}
+
+	w1 := s.Get("ns/pvc", seed, base, time.Hour)
+	if len(w1.Samples()) != 1 {
+		t.Fatalf("len(Samples()) = %d, want 1 after seeding", len(w1.Samples()))
+	}
+	w1.Add(base.Add(MinSampleInterval), 43, time.Hour)
+
+	w2 := s.Get("ns/pvc", nil, base.Add(MinSampleInterval), time.Hour)
+	if w2 != w1 {
+		t.Error("Get() returned a different Window for the same key, want the same instance")
+	}
+	if len(w2.Samples()) != 2 {
+		t.Errorf("len(Samples()) = %d, want 2 (seed should only apply the first time a key is seen)", len(w2.Samples()))
+	}
+}
@@ -0,0 +1,94 @@
+// Package quantity parses VolumeScaler's size and policy fields through
+// k8s.io/apimachinery's resource.Quantity instead of ad-hoc string
+// splitting, so values like "1500m", "10737418240", "2e9" and decimal SI
+// units ("10G") all work the same way kubectl and the API server accept
+// them. All arithmetic is done in bytes; callers only format back to a
+// quantity string for the final PVC patch.
+package quantity
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// DefaultGranularity is the size increment new PVC sizes are rounded up to.
+// The same increment is used for every PVC; the StorageClass is not
+// consulted.
+const DefaultGranularity int64 = 1 << 30 // 1Gi
+
+// ParseBytes parses a Kubernetes quantity string (e.g. "100Gi", "1500m",
+// "2e9") and returns its value in bytes.
+func ParseBytes(s string) (int64, error) {
+	q, err := resource.ParseQuantity(s)
+	if err != nil {
+		return 0, fmt.Errorf("parsing quantity %q: %w", s, err)
+	}
+	return q.Value(), nil
+}
+
+// Policy is either a percentage or an absolute byte quantity, letting a
+// VolumeScaler express a threshold or scale step as a fixed-headroom
+// policy ("10Gi") instead of only a percentage ("70%").
+type Policy struct {
+	percent float64
+	bytes   int64
+	isBytes bool
+}
+
+// ParsePolicy parses a threshold/scale field. A trailing "%" is treated as
+// a percentage; anything else is parsed as an absolute quantity.
+func ParsePolicy(s string) (Policy, error) {
+	if trimmed, ok := strings.CutSuffix(s, "%"); ok {
+		v, err := strconv.ParseFloat(trimmed, 64)
+		if err != nil {
+			return Policy{}, fmt.Errorf("parsing percentage %q: %w", s, err)
+		}
+		return Policy{percent: v}, nil
+	}
+
+	b, err := ParseBytes(s)
+	if err != nil {
+		return Policy{}, err
+	}
+	return Policy{bytes: b, isBytes: true}, nil
+}
+
+// ShouldScale reports whether usedBytes against a volume of capacityBytes
+// has crossed this policy's threshold: either utilization meeting a
+// percentage, or free space dropping below an absolute quantity.
+func (p Policy) ShouldScale(usedBytes, capacityBytes int64) bool {
+	if p.isBytes {
+		free := capacityBytes - usedBytes
+		return free < p.bytes
+	}
+	if capacityBytes == 0 {
+		return false
+	}
+	utilization := float64(usedBytes) / float64(capacityBytes) * 100
+	return utilization >= p.percent
+}
+
+// GrowBy returns how many bytes to grow a volume of capacityBytes by under
+// this policy: either a percentage of its current capacity, or a fixed
+// absolute quantity.
+func (p Policy) GrowBy(capacityBytes int64) int64 {
+	if p.isBytes {
+		return p.bytes
+	}
+	return int64(float64(capacityBytes) * (p.percent / 100.0))
+}
+
+// RoundUpBytes rounds size up to the next multiple of granularity.
+func RoundUpBytes(size, granularity int64) int64 {
+	if granularity <= 0 {
+		return size
+	}
+	remainder := size % granularity
+	if remainder == 0 {
+		return size
+	}
+	return size + (granularity - remainder)
+}
@@ -0,0 +1,104 @@
+package quantity
+
+import "testing"
+
+func TestParseBytes(t *testing.T) {
+	cases := []struct {
+		in   string
+		want int64
+	}{
+		{"100Gi", 100 * (1 << 30)},
+		{"1500m", 2}, // 1.5 bytes rounds up to the nearest whole byte
+		{"2e9", 2000000000},
+		{"10737418240", 10737418240},
+	}
+	for _, tc := range cases {
+		got, err := ParseBytes(tc.in)
+		if err != nil {
+			t.Errorf("ParseBytes(%q) returned error: %v", tc.in, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("ParseBytes(%q) = %d, want %d", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestParseBytesInvalid(t *testing.T) {
+	if _, err := ParseBytes("not-a-quantity"); err == nil {
+		t.Error("ParseBytes(\"not-a-quantity\") returned no error, want one")
+	}
+}
+
+func TestParsePolicyPercent(t *testing.T) {
+	p, err := ParsePolicy("70%")
+	if err != nil {
+		t.Fatalf("ParsePolicy(\"70%%\") returned error: %v", err)
+	}
+	if !p.ShouldScale(71, 100) {
+		t.Error("ShouldScale(71, 100) = false, want true at 70% threshold")
+	}
+	if p.ShouldScale(69, 100) {
+		t.Error("ShouldScale(69, 100) = true, want false at 70% threshold")
+	}
+}
+
+func TestParsePolicyAbsolute(t *testing.T) {
+	p, err := ParsePolicy("10Gi")
+	if err != nil {
+		t.Fatalf("ParsePolicy(\"10Gi\") returned error: %v", err)
+	}
+	capacity := int64(100 * (1 << 30))
+	usedBelowThreshold := capacity - 11*(1<<30) // 11Gi free
+	if p.ShouldScale(usedBelowThreshold, capacity) {
+		t.Error("ShouldScale with 11Gi free = true, want false at 10Gi threshold")
+	}
+	usedPastThreshold := capacity - 9*(1<<30) // 9Gi free
+	if !p.ShouldScale(usedPastThreshold, capacity) {
+		t.Error("ShouldScale with 9Gi free = false, want true at 10Gi threshold")
+	}
+}
+
+func TestParsePolicyInvalid(t *testing.T) {
+	if _, err := ParsePolicy("70%%"); err == nil {
+		t.Error("ParsePolicy(\"70%%\") returned no error, want one")
+	}
+	if _, err := ParsePolicy("garbage"); err == nil {
+		t.Error("ParsePolicy(\"garbage\") returned no error, want one")
+	}
+}
+
+func TestPolicyGrowBy(t *testing.T) {
+	percent, err := ParsePolicy("30%")
+	if err != nil {
+		t.Fatalf("ParsePolicy(\"30%%\") returned error: %v", err)
+	}
+	if got, want := percent.GrowBy(100), int64(30); got != want {
+		t.Errorf("GrowBy(100) = %d, want %d", got, want)
+	}
+
+	absolute, err := ParsePolicy("10Gi")
+	if err != nil {
+		t.Fatalf("ParsePolicy(\"10Gi\") returned error: %v", err)
+	}
+	if got, want := absolute.GrowBy(100), int64(10*(1<<30)); got != want {
+		t.Errorf("GrowBy(100) = %d, want %d", got, want)
+	}
+}
+
+func TestRoundUpBytes(t *testing.T) {
+	cases := []struct {
+		size, granularity, want int64
+	}{
+		{0, 1 << 30, 0},
+		{1, 1 << 30, 1 << 30},
+		{1 << 30, 1 << 30, 1 << 30},
+		{(1 << 30) + 1, 1 << 30, 2 << 30},
+		{5, 0, 5}, // non-positive granularity is a no-op
+	}
+	for _, tc := range cases {
+		if got := RoundUpBytes(tc.size, tc.granularity); got != tc.want {
+			t.Errorf("RoundUpBytes(%d, %d) = %d, want %d", tc.size, tc.granularity, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+// Package resize observes PVC resize progress after the controller patches
+// spec.resources.requests.storage, so callers know when the CSI
+// external-resizer's ControllerExpandVolume and kubelet's NodeExpandVolume
+// steps have actually finished instead of assuming success as soon as the
+// patch call returns.
+package resize
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// DefaultTimeout bounds how long Wait polls a PVC before giving up on
+	// a resize.
+	DefaultTimeout = 5 * time.Minute
+
+	minPollInterval = 2 * time.Second
+	maxPollInterval = 30 * time.Second
+)
+
+// Result reports the outcome of waiting for a PVC resize to complete.
+type Result struct {
+	Capacity  resource.Quantity
+	Completed bool
+}
+
+// Waiter polls a PVC until its observed capacity reaches the requested size
+// and any resize-related conditions have cleared.
+type Waiter struct {
+	kubeClient kubernetes.Interface
+	timeout    time.Duration
+}
+
+// NewWaiter returns a Waiter bounded by timeout, or DefaultTimeout if
+// timeout is zero.
+func NewWaiter(kubeClient kubernetes.Interface, timeout time.Duration) *Waiter {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &Waiter{kubeClient: kubeClient, timeout: timeout}
+}
+
+// Wait blocks until the PVC's status capacity reaches wantSize and all
+// PersistentVolumeClaimResizing/FileSystemResizePending conditions have
+// cleared, the timeout elapses, or ctx is cancelled. It returns the last
+// observed PVC so the caller can record its capacity/conditions, whether the
+// wait completed, timed out or was cancelled; the PVC is nil only when
+// fetching it failed.
+func (w *Waiter) Wait(ctx context.Context, namespace, name string, wantSize resource.Quantity) (*corev1.PersistentVolumeClaim, Result, error) {
+	deadline := time.Now().Add(w.timeout)
+	interval := minPollInterval
+
+	for {
+		pvc, err := w.kubeClient.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, Result{}, fmt.Errorf("getting PVC %s/%s: %w", namespace, name, err)
+		}
+
+		capacity := pvc.Status.Capacity[corev1.ResourceStorage]
+		if capacity.Cmp(wantSize) >= 0 && !hasPendingResizeCondition(pvc) {
+			return pvc, Result{Capacity: capacity, Completed: true}, nil
+		}
+
+		if time.Now().After(deadline) {
+			return pvc, Result{Capacity: capacity, Completed: false}, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return pvc, Result{Capacity: capacity}, ctx.Err()
+		case <-time.After(interval):
+		}
+
+		interval *= 2
+		if interval > maxPollInterval {
+			interval = maxPollInterval
+		}
+	}
+}
+
+func hasPendingResizeCondition(pvc *corev1.PersistentVolumeClaim) bool {
+	for _, cond := range pvc.Status.Conditions {
+		switch cond.Type {
+		case corev1.PersistentVolumeClaimResizing, corev1.PersistentVolumeClaimFileSystemResizePending:
+			if cond.Status == corev1.ConditionTrue {
+				return true
+			}
+		}
+	}
+	return false
+}